@@ -0,0 +1,86 @@
+package messenger
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	UnlinkAccountsPath string = "me/unlink_accounts"
+)
+
+// AccountLinkingStatus is the status of an account linking event
+type AccountLinkingStatus string
+
+// AccountLinkingStatus* are the account linking statuses
+const (
+	AccountLinkingStatusLinked   AccountLinkingStatus = "linked"
+	AccountLinkingStatusUnlinked AccountLinkingStatus = "unlinked"
+)
+
+// AccountLinking contains information specific to the account linking callback.
+// https://developers.facebook.com/docs/messenger-platform/reference/webhook-events/messaging_account_linking
+type AccountLinking struct {
+	Status AccountLinkingStatus `json:"status"`
+	// AuthorizationCode is the code passed to the redirect_uri on linking, it's not set on unlinking
+	AuthorizationCode string `json:"authorization_code,omitempty"`
+}
+
+// IsLinked reports whether the account has been linked
+func (a AccountLinking) IsLinked() bool {
+	return a.Status == AccountLinkingStatusLinked
+}
+
+type accountLinking struct {
+	//Recipient is Page Scoped ID
+	Recipient string `json:"recipient"`
+}
+
+// unlinkAccount represents the body of the unlink accounts request
+type unlinkAccount struct {
+	PSID string `json:"psid"`
+}
+
+// unlinkAccountResponse represents the response of the unlink accounts request
+type unlinkAccountResponse struct {
+	Result string `json:"result"`
+}
+
+// GetPSIDByAccountLinkingToken returns the page scoped id of the user from the account_linking_token
+// passed to the account linking url, so the user's account can be linked to the PSID.
+// https://developers.facebook.com/docs/messenger-platform/identity/account-linking
+func (c *controller) GetPSIDByAccountLinkingToken(accessToken, accountLinkingToken string) (string, error) {
+	if accountLinkingToken == "" {
+		return "", errors.New("account linking token is empty")
+	}
+
+	uri := fmt.Sprintf("%s/%s/me?fields=recipient&account_linking_token=%s&access_token=%s",
+		GraphAPI, c.graphAPIVersion, url.QueryEscape(accountLinkingToken), accessToken)
+
+	var response accountLinking
+	if err := c.doGraphRequest(http.MethodGet, uri, nil, &response); err != nil {
+		return "", errors.Wrap(err, "GetPSIDByAccountLinkingToken")
+	}
+	if response.Recipient == "" {
+		return "", errors.New("GetPSIDByAccountLinkingToken: recipient is missing from the response")
+	}
+	return response.Recipient, nil
+}
+
+// UnlinkAccount unlinks the user's account from the page, an account_linking webhook event with unlinked status is sent.
+func (c *controller) UnlinkAccount(accessToken, psid string) error {
+	if psid == "" {
+		return errors.New("psid is empty")
+	}
+
+	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, UnlinkAccountsPath, accessToken)
+
+	var response unlinkAccountResponse
+	if err := c.doGraphRequest(http.MethodPost, uri, unlinkAccount{PSID: psid}, &response); err != nil {
+		return errors.Wrapf(err, "UnlinkAccount(%s)", psid)
+	}
+	return nil
+}
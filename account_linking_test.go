@@ -0,0 +1,75 @@
+package messenger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestAccountLinkingEvent(t *testing.T) {
+	payload := `{"object":"page","entry":[{"id":"100","time":1,"messaging":[
+		{"sender":{"id":"400"},"recipient":{"id":"100"},"timestamp":1,"account_linking":{"status":"linked","authorization_code":"code"}},
+		{"sender":{"id":"400"},"recipient":{"id":"100"},"timestamp":2,"account_linking":{"status":"unlinked"}}
+	]}]}`
+	var event UpstreamEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []AccountLinking
+	d := Dispatcher{AccountLinking: func(e Entry, a AccountLinking) error {
+		events = append(events, a)
+		return nil
+	}}
+	if err := d.Dispatch(event); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 || !events[0].IsLinked() || events[0].AuthorizationCode != "code" || events[1].IsLinked() {
+		t.Errorf("unexpected events: %+v", events)
+	}
+	if key := event.Entries[0].Messaging[0].DedupKey(); key != "account_linking:400:100:1" {
+		t.Errorf("unexpected dedup key: %s", key)
+	}
+}
+
+func TestGetPSIDByAccountLinkingToken(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/"+GraphAPIVersion+"/me" || q.Get("fields") != "recipient" || q.Get("account_linking_token") != "a+b/c" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		w.Write([]byte(`{"id":"100","recipient":"400"}`))
+	})
+
+	psid, err := c.GetPSIDByAccountLinkingToken("token", "a+b/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if psid != "400" {
+		t.Errorf("unexpected psid: %s", psid)
+	}
+}
+
+func TestUnlinkAccount(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path != "/"+GraphAPIVersion+"/"+UnlinkAccountsPath || string(body) != `{"psid":"400"}` {
+			t.Errorf("unexpected request: %s %s", r.URL.Path, body)
+		}
+		if r.URL.Query().Get("access_token") == "expired" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"Error validating access token","code":190}}`))
+			return
+		}
+		w.Write([]byte(`{"result":"unlink account success"}`))
+	})
+
+	if err := c.UnlinkAccount("token", "400"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UnlinkAccount("expired", "400"); !isGraphErrorCode(err, 190) {
+		t.Errorf("graph api error expected, got %v", err)
+	}
+}
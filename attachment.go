@@ -57,7 +57,7 @@ type Location struct {
 
 // Resource ...
 type Resource struct {
-	URL      string `json:"url"`
+	URL      string `json:"url,omitempty"`
 	Reusable bool   `json:"is_reusable,omitempty"`
 }
 
@@ -65,3 +65,30 @@ type Resource struct {
 type ReusableAttachment struct {
 	AttachmentID string `json:"attachment_id"`
 }
+
+// MessageAttachmentsPath is the path of the Attachment Upload API
+// https://developers.facebook.com/docs/messenger-platform/reference/attachment-upload-api
+const MessageAttachmentsPath = "me/message_attachments"
+
+// AttachmentUpload represents a request of the Attachment Upload API
+type AttachmentUpload struct {
+	Message struct {
+		Attachment UploadedAttachment `json:"attachment"`
+	} `json:"message"`
+}
+
+// UploadedAttachment describes the media uploaded with the Attachment Upload API
+type UploadedAttachment struct {
+	Type    AttachmentType `json:"type"`
+	Payload Resource       `json:"payload"`
+}
+
+// NewAttachmentWithID returns an attachment referencing media uploaded with the Attachment Upload API
+func NewAttachmentWithID(t AttachmentType, attachmentID string) *Attachment {
+	// marshaling a struct with a single string field never fails
+	payload, _ := json.Marshal(ReusableAttachment{AttachmentID: attachmentID})
+	return &Attachment{
+		Type:    t,
+		Payload: payload,
+	}
+}
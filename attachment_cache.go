@@ -0,0 +1,183 @@
+package messenger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// AttachmentStore stores reusable attachment ids by cache key, implement it to share the ids between processes
+type AttachmentStore interface {
+	// Get returns the attachment id stored for the key, ok is false if the key is not found
+	Get(key string) (attachmentID string, ok bool, err error)
+	Set(key, attachmentID string) error
+	Delete(key string) error
+}
+
+// MemoryAttachmentStore is an AttachmentStore keeping the ids in memory
+type MemoryAttachmentStore struct {
+	mu  sync.RWMutex
+	ids map[string]string
+}
+
+// NewMemoryAttachmentStore returns an empty MemoryAttachmentStore
+func NewMemoryAttachmentStore() *MemoryAttachmentStore {
+	return &MemoryAttachmentStore{
+		ids: make(map[string]string),
+	}
+}
+
+// Get returns the attachment id stored for the key
+func (s *MemoryAttachmentStore) Get(key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.ids[key]
+	return id, ok, nil
+}
+
+// Set stores the attachment id for the key
+func (s *MemoryAttachmentStore) Set(key, attachmentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[key] = attachmentID
+	return nil
+}
+
+// Delete removes the key from the store
+func (s *MemoryAttachmentStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ids, key)
+	return nil
+}
+
+// AttachmentCache uploads media with the Attachment Upload API only once and reuses the returned attachment ids.
+// Media sent by URL is keyed by its type and URL, media uploaded from memory by its type and content hash.
+type AttachmentCache struct {
+	controller  Controller
+	store       AttachmentStore
+	accessToken string
+}
+
+// NewAttachmentCache returns an AttachmentCache uploading the media to the page of the access token
+func NewAttachmentCache(c Controller, store AttachmentStore, accessToken string) *AttachmentCache {
+	return &AttachmentCache{
+		controller:  c,
+		store:       store,
+		accessToken: accessToken,
+	}
+}
+
+// URLKey returns the cache key of the media available at url
+func URLKey(t AttachmentType, url string) string {
+	return string(t) + ":url:" + url
+}
+
+// ContentKey returns the cache key of the media with the given content
+func ContentKey(t AttachmentType, content []byte) string {
+	sum := sha256.Sum256(content)
+	return string(t) + ":sha256:" + hex.EncodeToString(sum[:])
+}
+
+// AttachmentIDForURL returns the attachment id of the media available at url, uploading it if it's not cached yet
+func (c *AttachmentCache) AttachmentIDForURL(t AttachmentType, url string) (string, error) {
+	return c.attachmentID(URLKey(t, url), func() (string, error) {
+		return c.controller.UploadAttachment(c.accessToken, t, url)
+	})
+}
+
+// AttachmentIDForContent returns the attachment id of the media with the given content, uploading it if it's not cached yet
+func (c *AttachmentCache) AttachmentIDForContent(t AttachmentType, filename string, content []byte) (string, error) {
+	return c.attachmentID(ContentKey(t, content), func() (string, error) {
+		return c.controller.UploadAttachmentFromReader(c.accessToken, t, filename, bytes.NewReader(content))
+	})
+}
+
+// Rewrite returns a copy of the query whose image, video, audio or file attachment sent by URL
+// is replaced by its reusable attachment id. Other queries are returned unchanged.
+func (c *AttachmentCache) Rewrite(q MessageQuery) (MessageQuery, error) {
+	q, _, err := c.rewrite(q)
+	return q, err
+}
+
+// Send rewrites the query like Rewrite and sends it with the send function.
+// If Graph API reports the cached attachment id as invalid, the media is uploaded again and the query is resent once.
+func (c *AttachmentCache) Send(q MessageQuery, send func(MessageQuery) error) error {
+	rewritten, key, err := c.rewrite(q)
+	if err != nil {
+		return err
+	}
+
+	err = send(rewritten)
+	if key == "" || !IsInvalidAttachmentError(err) {
+		return err
+	}
+
+	if err := c.store.Delete(key); err != nil {
+		return errors.Wrapf(err, "AttachmentCache.Send/store.Delete(%s)", key)
+	}
+	rewritten, _, err = c.rewrite(q)
+	if err != nil {
+		return err
+	}
+	return send(rewritten)
+}
+
+// rewrite does the Rewrite and returns the cache key of the attachment if it's been rewritten
+func (c *AttachmentCache) rewrite(q MessageQuery) (MessageQuery, string, error) {
+	if q.Message == nil || q.Message.Attachment == nil {
+		return q, "", nil
+	}
+
+	attachment := *q.Message.Attachment
+	switch attachment.Type {
+	case AttachmentTypeImage, AttachmentTypeVideo, AttachmentTypeAudio, AttachmentTypeFile:
+	default:
+		return q, "", nil
+	}
+
+	var resource Resource
+	if err := json.Unmarshal(attachment.Payload, &resource); err != nil || resource.URL == "" {
+		return q, "", nil
+	}
+
+	id, err := c.AttachmentIDForURL(attachment.Type, resource.URL)
+	if err != nil {
+		return q, "", err
+	}
+
+	message := *q.Message
+	message.Attachment = NewAttachmentWithID(attachment.Type, id)
+	q.Message = &message
+	return q, URLKey(attachment.Type, resource.URL), nil
+}
+
+func (c *AttachmentCache) attachmentID(key string, upload func() (string, error)) (string, error) {
+	id, ok, err := c.store.Get(key)
+	if err != nil {
+		return "", errors.Wrapf(err, "AttachmentCache/store.Get(%s)", key)
+	}
+	if ok {
+		return id, nil
+	}
+
+	id, err = upload()
+	if err != nil {
+		return "", errors.Wrapf(err, "AttachmentCache/upload(%s)", key)
+	}
+	if err := c.store.Set(key, id); err != nil {
+		return "", errors.Wrapf(err, "AttachmentCache/store.Set(%s, %s)", key, id)
+	}
+	return id, nil
+}
+
+// IsInvalidAttachmentError reports whether err is the Graph API error rejecting an attachment id,
+// i.e. the invalid parameter error with the upload attachment failure subcode.
+func IsInvalidAttachmentError(err error) bool {
+	graphErr, ok := errors.Cause(err).(Error)
+	return ok && graphErr.Code == ErrorCodeInvalidParameter && graphErr.ErrorSubcode == ErrorSubcodeAttachmentUploadFailure
+}
@@ -0,0 +1,97 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAttachmentCacheSend(t *testing.T) {
+	uploads := 0
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		if uploads == 1 {
+			w.Write([]byte(`{"attachment_id":"1"}`))
+			return
+		}
+		w.Write([]byte(`{"attachment_id":"2"}`))
+	})
+	cache := NewAttachmentCache(c, NewMemoryAttachmentStore(), "token")
+
+	q, err := NewMessageBuilder().To("123").Media(AttachmentTypeImage, "https://example.com/brand.png").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []string
+	send := func(q MessageQuery) error {
+		var payload ReusableAttachment
+		if err := json.Unmarshal(q.Message.Attachment.Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, payload.AttachmentID)
+		if payload.AttachmentID == "1" && len(sent) > 2 {
+			return Error{Code: ErrorCodeInvalidParameter, ErrorSubcode: ErrorSubcodeAttachmentUploadFailure, Message: "Upload attachment failure"}
+		}
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := cache.Send(q, send); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if uploads != 2 {
+		t.Errorf("expected 2 uploads, got %d", uploads)
+	}
+	if len(sent) != 4 || sent[0] != "1" || sent[1] != "1" || sent[2] != "1" || sent[3] != "2" {
+		t.Errorf("unexpected sent attachment ids: %v", sent)
+	}
+	if string(q.Message.Attachment.Payload) != `{"url":"https://example.com/brand.png"}` {
+		t.Error("original query is modified")
+	}
+}
+
+func TestAttachmentCacheRewriteSkips(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected upload")
+	})
+	cache := NewAttachmentCache(c, NewMemoryAttachmentStore(), "token")
+
+	queries := []MessageQuery{
+		{Message: &SendMessage{Text: "Hello"}},
+		{Message: &SendMessage{Attachment: NewAttachmentWithID(AttachmentTypeImage, "1")}},
+		{Message: &SendMessage{Attachment: &Attachment{Type: AttachmentTypeTemplate, Payload: json.RawMessage(`{"template_type":"button","url":"x"}`)}}},
+		{Action: SenderActionTypingOn},
+	}
+	for _, q := range queries {
+		rewritten, err := cache.Rewrite(q)
+		if err != nil {
+			t.Error(err)
+		}
+		if rewritten.Message != q.Message {
+			t.Errorf("query is rewritten: %+v", q)
+		}
+	}
+}
+
+func TestIsInvalidAttachmentError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{Error{Code: ErrorCodeInvalidParameter, ErrorSubcode: ErrorSubcodeAttachmentUploadFailure}, true},
+		{errors.Wrap(Error{Code: ErrorCodeInvalidParameter, ErrorSubcode: ErrorSubcodeAttachmentUploadFailure}, "send"), true},
+		{Error{Code: ErrorCodeInvalidParameter, Message: "(#100) Invalid attachment_id parameter in message"}, false},
+		{Error{Code: ErrorCodePermissionDenied, ErrorSubcode: ErrorSubcodeAttachmentUploadFailure}, false},
+		{errors.New("attachment"), false},
+	}
+	for _, test := range tests {
+		if IsInvalidAttachmentError(test.err) != test.expected {
+			t.Errorf("IsInvalidAttachmentError(%v) != %t", test.err, test.expected)
+		}
+	}
+}
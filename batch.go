@@ -0,0 +1,273 @@
+package messenger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BatchRequestLimit is the maximum number of requests in a Graph API batch request
+// https://developers.facebook.com/docs/graph-api/batch-requests
+const BatchRequestLimit = 50
+
+// Batch errors
+var (
+	ErrBatchRequestLimitExceeded = errors.New("batch request limit exceeded")
+	ErrBatchRequestNotExecuted   = errors.New("batch request not executed, the request it depends on has failed")
+)
+
+// BatchRequest is a single request of a Graph API batch request.
+// Later requests can reference the result of a named request with BatchResultRef in their RelativeURL or Body.
+type BatchRequest struct {
+	Method      string `json:"method"`
+	RelativeURL string `json:"relative_url"`
+	// Body is the URL encoded body of POST requests, see BatchBody
+	Body      string `json:"body,omitempty"`
+	Name      string `json:"name,omitempty"`
+	DependsOn string `json:"depends_on,omitempty"`
+	// OmitResponseOnSuccess controls whether the response of a named request is returned.
+	// Graph API omits it by default, except if Result is set.
+	OmitResponseOnSuccess *bool `json:"omit_response_on_success,omitempty"`
+	// Result, if not nil, is where the response body is decoded to when the request succeeds
+	Result interface{} `json:"-"`
+}
+
+// NewBatchGet returns a GET request of the relative url (e.g. "<PSID>?fields=name") decoding its response into result
+func NewBatchGet(relativeURL string, result interface{}) BatchRequest {
+	return BatchRequest{
+		Method:      http.MethodGet,
+		RelativeURL: relativeURL,
+		Result:      result,
+	}
+}
+
+// NewBatchPost returns a POST request of the relative url with the body encoded by BatchBody, decoding its response into result
+func NewBatchPost(relativeURL string, body interface{}, result interface{}) (BatchRequest, error) {
+	encoded, err := BatchBody(body)
+	if err != nil {
+		return BatchRequest{}, err
+	}
+	return BatchRequest{
+		Method:      http.MethodPost,
+		RelativeURL: relativeURL,
+		Body:        encoded,
+		Result:      result,
+	}, nil
+}
+
+// NewBatchDelete returns a DELETE request of the relative url
+func NewBatchDelete(relativeURL string) BatchRequest {
+	return BatchRequest{
+		Method:      http.MethodDelete,
+		RelativeURL: relativeURL,
+	}
+}
+
+// BatchBody URL encodes the JSON object form of v: string values are sent as they are, other values JSON encoded.
+func BatchBody(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Wrapf(err, "BatchBody/json.Marshal(%v)", v)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", errors.Wrap(err, "BatchBody: body has to be a JSON object")
+	}
+
+	form := url.Values{}
+	for key, value := range fields {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			form.Set(key, s)
+			continue
+		}
+		form.Set(key, string(value))
+	}
+	return form.Encode(), nil
+}
+
+// BatchResultRef returns the reference to a property of the named request's result, e.g.
+// BatchResultRef("persona", "$.id") or BatchResultRef("conversations", "$.data.*.id")
+func BatchResultRef(name, jsonPath string) string {
+	return fmt.Sprintf("{result=%s:%s}", name, jsonPath)
+}
+
+// BatchResponse is the response of a single request of a Graph API batch request
+type BatchResponse struct {
+	Code int    `json:"code"`
+	Body string `json:"body"`
+}
+
+// Decode returns the graph api Error of the response if its code is not 200, or decodes its body into v otherwise.
+func (r *BatchResponse) Decode(v interface{}) error {
+	if r.Code != http.StatusOK {
+		er := RawError{}
+		if err := json.Unmarshal([]byte(r.Body), &er); err != nil || er.Error == nil {
+			return errors.Errorf("response code != http.StatusOK: %d %s", r.Code, r.Body)
+		}
+		return *er.Error
+	}
+
+	if v == nil {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal([]byte(r.Body), v), "json.Unmarshal(%s)", r.Body)
+}
+
+// BatchError is the error of a single request of a batch request
+type BatchError struct {
+	Index int
+	Name  string
+	// SkippedBecause is the name of the failed request this one depended on, set if it has been skipped because of it
+	SkippedBecause string
+	Err            error
+}
+
+// Error ...
+func (e BatchError) Error() string {
+	if e.SkippedBecause != "" {
+		return fmt.Sprintf("batch request %d (%s): skipped, the request %s it depends on has failed", e.Index, e.Name, e.SkippedBecause)
+	}
+	if e.Name != "" {
+		return fmt.Sprintf("batch request %d (%s): %v", e.Index, e.Name, e.Err)
+	}
+	return fmt.Sprintf("batch request %d: %v", e.Index, e.Err)
+}
+
+// Cause returns the error of the request, so errors.Cause returns the graph api Error
+func (e BatchError) Cause() error {
+	return e.Err
+}
+
+// BatchErrors lists the failed requests of a batch request
+type BatchErrors []BatchError
+
+// Error ...
+func (e BatchErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ByIndex returns the error of the request at index i, nil if it succeeded
+func (e BatchErrors) ByIndex(i int) error {
+	for _, err := range e {
+		if err.Index == i {
+			return err
+		}
+	}
+	return nil
+}
+
+// Batch sends up to 50 requests in one Graph API batch request.
+// The responses are returned in the order of the requests, a response is nil if it has been omitted or not executed.
+// The result of every successful request having a Result is decoded into it.
+// If some of the requests failed, the returned error is BatchErrors with their graph api Errors.
+// The requests skipped because a request they depend on has failed are reported with ErrBatchRequestNotExecuted.
+func (c *controller) Batch(accessToken string, requests []BatchRequest) ([]*BatchResponse, error) {
+	if len(requests) > BatchRequestLimit {
+		return nil, errors.Wrapf(ErrBatchRequestLimitExceeded, "%d > %d", len(requests), BatchRequestLimit)
+	}
+
+	sent := make([]BatchRequest, len(requests))
+	for i, r := range requests {
+		if r.Result != nil && r.OmitResponseOnSuccess == nil {
+			omit := false
+			r.OmitResponseOnSuccess = &omit
+		}
+		sent[i] = r
+	}
+
+	enc, err := json.Marshal(sent)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Batch/json.Marshal(%v)", sent)
+	}
+
+	form := url.Values{}
+	form.Set("access_token", accessToken)
+	form.Set("batch", string(enc))
+	form.Set("include_headers", "false")
+
+	uri := fmt.Sprintf("%s/%s/", GraphAPI, c.graphAPIVersion)
+	req, err := http.NewRequest(http.MethodPost, uri, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Batch/http.NewRequest(%v, %v)", http.MethodPost, uri)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Batch/c.httpClient.Do(%v)", req)
+	}
+	defer resp.Body.Close()
+
+	var responses []*BatchResponse
+	if err := decodeResponse(resp, &responses); err != nil {
+		return nil, errors.Wrapf(err, "Batch - sent: %s", string(enc))
+	}
+	if len(responses) != len(requests) {
+		return nil, errors.Errorf("Batch: %d responses received for %d requests", len(responses), len(requests))
+	}
+
+	var batchErrs BatchErrors
+	failed := make(map[string]bool)
+	for i, r := range sent {
+		batchErr := BatchError{Index: i, Name: r.Name}
+		if dependency := failedDependency(r, failed); dependency != "" && responses[i] == nil {
+			batchErr.SkippedBecause, batchErr.Err = dependency, ErrBatchRequestNotExecuted
+		} else {
+			batchErr.Err = decodeBatchResponse(r, responses[i])
+		}
+		if batchErr.Err == nil {
+			continue
+		}
+
+		batchErrs = append(batchErrs, batchErr)
+		if r.Name != "" {
+			failed[r.Name] = true
+		}
+	}
+	if len(batchErrs) > 0 {
+		return responses, batchErrs
+	}
+	return responses, nil
+}
+
+// resultReference matches a JSONPath reference to the result of a named request.
+var resultReference = regexp.MustCompile(`\{result=([^:}]+):`)
+
+// failedDependency returns the name of the failed request the request depends on,
+// either by its DependsOn or by referencing its result, or an empty string.
+func failedDependency(r BatchRequest, failed map[string]bool) string {
+	if failed[r.DependsOn] {
+		return r.DependsOn
+	}
+	for _, s := range []string{r.RelativeURL, r.Body} {
+		for _, ref := range resultReference.FindAllStringSubmatch(s, -1) {
+			if failed[ref[1]] {
+				return ref[1]
+			}
+		}
+	}
+	return ""
+}
+
+// decodeBatchResponse decodes the response into the request's Result.
+// A missing response is an error only if the request's response hasn't been omitted on purpose.
+func decodeBatchResponse(r BatchRequest, resp *BatchResponse) error {
+	if resp == nil {
+		if r.OmitResponseOnSuccess == nil && r.Name != "" || r.OmitResponseOnSuccess != nil && *r.OmitResponseOnSuccess {
+			return nil
+		}
+		return ErrBatchRequestNotExecuted
+	}
+	return resp.Decode(r.Result)
+}
@@ -0,0 +1,166 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBatch(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		var requests []map[string]interface{}
+		if err := json.Unmarshal([]byte(r.FormValue("batch")), &requests); err != nil {
+			t.Error(err)
+			return
+		}
+		if len(requests) != 4 {
+			t.Errorf("unexpected requests: %v", requests)
+			return
+		}
+		if requests[0]["name"] != "persona" || requests[0]["body"] != "name=Agent+Smith&profile_picture_url=https%3A%2F%2Fexample.com%2Fa.png" {
+			t.Errorf("unexpected first request: %v", requests[0])
+		}
+		if requests[1]["depends_on"] != "persona" || requests[1]["relative_url"] != "{result=persona:$.id}" {
+			t.Errorf("unexpected dependent request: %v", requests[1])
+		}
+		if requests[1]["omit_response_on_success"] != false {
+			t.Errorf("response of a request with result should be kept: %v", requests[1])
+		}
+
+		w.Write([]byte(`[
+			null,
+			{"code":200,"body":"{\"id\":\"42\",\"name\":\"Agent Smith\"}"},
+			{"code":400,"body":"{\"error\":{\"message\":\"Unsupported get request\",\"code\":100}}"},
+			null
+		]`))
+	})
+
+	post, err := NewBatchPost("me/personas", map[string]string{
+		"name":                "Agent Smith",
+		"profile_picture_url": "https://example.com/a.png",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post.Name = "persona"
+
+	var persona struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	get := NewBatchGet(BatchResultRef("persona", "$.id"), &persona)
+	get.DependsOn = "persona"
+
+	responses, err := c.Batch("token", []BatchRequest{
+		post,
+		get,
+		NewBatchGet("missing", nil),
+		NewBatchDelete("42"),
+	})
+
+	if len(responses) != 4 || responses[0] != nil || responses[1].Code != 200 {
+		t.Errorf("unexpected responses: %v", responses)
+	}
+	if persona.ID != "42" || persona.Name != "Agent Smith" {
+		t.Errorf("unexpected result: %+v", persona)
+	}
+
+	batchErrs, ok := err.(BatchErrors)
+	if !ok || len(batchErrs) != 2 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if graphErr, ok := errors.Cause(batchErrs.ByIndex(2)).(Error); !ok || graphErr.Code != ErrorCodeInvalidParameter {
+		t.Errorf("graph api error expected, got %v", batchErrs.ByIndex(2))
+	}
+	if errors.Cause(batchErrs.ByIndex(3)) != ErrBatchRequestNotExecuted {
+		t.Errorf("not executed error expected, got %v", batchErrs.ByIndex(3))
+	}
+	if batchErrs.ByIndex(0) != nil || batchErrs.ByIndex(1) != nil {
+		t.Errorf("unexpected errors: %v", batchErrs)
+	}
+}
+
+func TestBatchSkippedDependents(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"code":400,"body":"{\"error\":{\"message\":\"Invalid parameter\",\"code\":100}}"},
+			null,
+			null
+		]`))
+	})
+
+	first := NewBatchDelete("42")
+	first.Name = "first"
+	second := NewBatchGet(BatchResultRef("first", "$.id"), nil)
+	second.Name = "second"
+	third := NewBatchDelete("43")
+	third.DependsOn = "second"
+
+	_, err := c.Batch("token", []BatchRequest{first, second, third})
+	batchErrs, ok := err.(BatchErrors)
+	if !ok || len(batchErrs) != 3 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batchErrs[1].SkippedBecause != "first" || batchErrs[2].SkippedBecause != "second" {
+		t.Errorf("skipped requests should be reported with their failed dependency: %v", batchErrs)
+	}
+	if errors.Cause(batchErrs.ByIndex(2)) != ErrBatchRequestNotExecuted {
+		t.Errorf("not executed error expected, got %v", batchErrs.ByIndex(2))
+	}
+}
+
+func TestBatchSkippedFirstReference(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"code":400,"body":"{\"error\":{\"message\":\"Invalid parameter\",\"code\":100}}"},
+			{"code":400,"body":"{\"error\":{\"message\":\"Invalid parameter\",\"code\":100}}"},
+			null
+		]`))
+	})
+
+	first := NewBatchDelete("42")
+	first.Name = "first"
+	second := NewBatchDelete("43")
+	second.Name = "second"
+	third := NewBatchGet("?ids="+BatchResultRef("second", "$.id")+","+BatchResultRef("first", "$.id"), nil)
+
+	for i := 0; i < 10; i++ {
+		_, err := c.Batch("token", []BatchRequest{first, second, third})
+		batchErrs, ok := err.(BatchErrors)
+		if !ok || len(batchErrs) != 3 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if batchErrs[2].SkippedBecause != "second" {
+			t.Errorf("the first failed reference should be reported, got %q", batchErrs[2].SkippedBecause)
+		}
+	}
+}
+
+func TestBatchLimit(t *testing.T) {
+	c := NewController()
+	_, err := c.Batch("token", make([]BatchRequest, BatchRequestLimit+1))
+	if errors.Cause(err) != ErrBatchRequestLimitExceeded {
+		t.Errorf("limit error expected, got %v", err)
+	}
+}
+
+func TestBatchBody(t *testing.T) {
+	body, err := BatchBody(struct {
+		Recipient Recipient `json:"recipient"`
+		Text      string    `json:"text"`
+	}{Recipient{ID: "1"}, "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, _ := url.ParseQuery(body)
+	if values.Get("text") != "hello" || values.Get("recipient") != `{"id":"1"}` {
+		t.Errorf("unexpected body: %s", body)
+	}
+
+	if _, err := BatchBody("text"); err == nil {
+		t.Error("error expected for a non object body")
+	}
+}
@@ -0,0 +1,227 @@
+package messenger
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// MessageBuilder builds a MessageQuery step by step.
+// The methods can be chained, errors are collected and returned by Build.
+type MessageBuilder struct {
+	query   MessageQuery
+	message SendMessage
+	err     error
+}
+
+// NewMessageBuilder returns an empty MessageBuilder
+func NewMessageBuilder() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// To sets the page scoped ID of the recipient
+func (b *MessageBuilder) To(psid string) *MessageBuilder {
+	b.query.Recipient = Recipient{ID: psid}
+	return b
+}
+
+// ToPhoneNumber sets the phone number of the recipient
+func (b *MessageBuilder) ToPhoneNumber(phoneNumber string) *MessageBuilder {
+	b.query.Recipient = Recipient{PhoneNumber: phoneNumber}
+	return b
+}
+
+// ToComment makes the message a private reply to the author of the comment
+func (b *MessageBuilder) ToComment(commentID string) *MessageBuilder {
+	b.query.Recipient = Recipient{CommentID: commentID}
+	return b
+}
+
+// ToPost makes the message a private reply to the author of the visitor post
+func (b *MessageBuilder) ToPost(postID string) *MessageBuilder {
+	b.query.Recipient = Recipient{PostID: postID}
+	return b
+}
+
+// ToRecipient sets the recipient
+func (b *MessageBuilder) ToRecipient(r Recipient) *MessageBuilder {
+	b.query.Recipient = r
+	return b
+}
+
+// Text sets the text of the message
+func (b *MessageBuilder) Text(text string) *MessageBuilder {
+	b.message.Text = text
+	return b
+}
+
+// Media attaches an image, video, audio or file by its URL
+func (b *MessageBuilder) Media(t AttachmentType, url string) *MessageBuilder {
+	if url == "" {
+		return b.fail(errors.New("media url is empty"))
+	}
+	return b.attach(t, Resource{URL: url})
+}
+
+// AttachmentID attaches an image, video, audio or file previously uploaded with the Attachment Upload API
+func (b *MessageBuilder) AttachmentID(t AttachmentType, attachmentID string) *MessageBuilder {
+	if attachmentID == "" {
+		return b.fail(errors.New("attachment id is empty"))
+	}
+	b.message.Attachment = NewAttachmentWithID(t, attachmentID)
+	return b
+}
+
+// Template attaches a template, e.g. a template.GenericTemplate.
+// If the template_type of the template isn't set, the result of its Type() method is used.
+// Templates having a Validate() error method are validated.
+func (b *MessageBuilder) Template(template interface{}) *MessageBuilder {
+	if v, ok := template.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return b.fail(errors.Wrap(err, "invalid template"))
+		}
+	}
+
+	enc, err := json.Marshal(template)
+	if err != nil {
+		return b.fail(errors.Wrapf(err, "json.Marshal(%v)", template))
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(enc, &payload); err != nil || payload == nil {
+		return b.fail(errors.Errorf("template has to be a JSON object: %s", enc))
+	}
+	var templateType string
+	json.Unmarshal(payload["template_type"], &templateType)
+	if templateType == "" {
+		templateType = typeOfTemplate(template)
+		if templateType == "" {
+			return b.fail(errors.New("template_type of the template is empty"))
+		}
+		payload["template_type"], _ = json.Marshal(templateType)
+		if enc, err = json.Marshal(payload); err != nil {
+			return b.fail(errors.Wrapf(err, "json.Marshal(%v)", payload))
+		}
+	}
+
+	b.message.Attachment = &Attachment{
+		Type:    AttachmentTypeTemplate,
+		Payload: enc,
+	}
+	return b
+}
+
+// typeOfTemplate returns the result of the template's Type() method, e.g. template.TemplateTypeGeneric.
+// The template package can't be imported here, so the method is looked up by reflection.
+func typeOfTemplate(template interface{}) string {
+	method := reflect.ValueOf(template).MethodByName("Type")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 || method.Type().Out(0).Kind() != reflect.String {
+		return ""
+	}
+	return method.Call(nil)[0].String()
+}
+
+// QuickReplies appends quick replies to the message
+func (b *MessageBuilder) QuickReplies(qr ...QuickReply) *MessageBuilder {
+	b.message.QuickReplies = append(b.message.QuickReplies, qr...)
+	return b
+}
+
+// TextQuickReply appends a text quick reply to the message
+func (b *MessageBuilder) TextQuickReply(title, payload string) *MessageBuilder {
+	return b.QuickReplies(QuickReply{
+		ContentType: ContentTypeText,
+		Title:       title,
+		Payload:     payload,
+	})
+}
+
+// MessagingType sets the messaging type, MessagingTypeResponse is used if it's not set
+func (b *MessageBuilder) MessagingType(t MessagingType) *MessageBuilder {
+	b.query.MessagingType = t
+	return b
+}
+
+// Tag sets the message tag and the MessagingTypeMessageTag messaging type
+func (b *MessageBuilder) Tag(tag MessageTag) *MessageBuilder {
+	b.query.MessagingType = MessagingTypeMessageTag
+	b.query.Tag = tag
+	return b
+}
+
+// NotificationType sets the notification type
+func (b *MessageBuilder) NotificationType(t NotificationType) *MessageBuilder {
+	b.query.NotificationType = t
+	return b
+}
+
+// Persona sets the persona the message is sent as
+func (b *MessageBuilder) Persona(personaID string) *MessageBuilder {
+	b.query.PersonaID = personaID
+	return b
+}
+
+// Metadata sets the metadata of the message
+func (b *MessageBuilder) Metadata(metadata string) *MessageBuilder {
+	b.message.Metadata = metadata
+	return b
+}
+
+// Build validates and returns the MessageQuery
+func (b *MessageBuilder) Build() (MessageQuery, error) {
+	if b.err != nil {
+		return MessageQuery{}, b.err
+	}
+
+	q := b.query
+	if q.Recipient == (Recipient{}) {
+		return MessageQuery{}, errors.New("recipient is empty")
+	}
+
+	if b.message.Text == "" && b.message.Attachment == nil {
+		return MessageQuery{}, errors.New("message has neither text nor attachment")
+	}
+	if b.message.Text != "" && b.message.Attachment != nil {
+		return MessageQuery{}, errors.New("message has both text and attachment")
+	}
+
+	if q.MessagingType == "" {
+		q.MessagingType = MessagingTypeResponse
+	}
+	if q.MessagingType == MessagingTypeMessageTag && q.Tag == "" {
+		return MessageQuery{}, errors.New("tag is empty for MESSAGE_TAG messaging type")
+	}
+	if q.MessagingType != MessagingTypeMessageTag && q.Tag != "" {
+		return MessageQuery{}, errors.Errorf("tag is set for %s messaging type", q.MessagingType)
+	}
+
+	message := b.message
+	if err := message.Validate(); err != nil {
+		return MessageQuery{}, err
+	}
+	q.Message = &message
+
+	return q, nil
+}
+
+func (b *MessageBuilder) attach(t AttachmentType, payload interface{}) *MessageBuilder {
+	enc, err := json.Marshal(payload)
+	if err != nil {
+		return b.fail(errors.Wrapf(err, "json.Marshal(%v)", payload))
+	}
+
+	b.message.Attachment = &Attachment{
+		Type:    t,
+		Payload: enc,
+	}
+	return b
+}
+
+// fail keeps the first error occurred while building
+func (b *MessageBuilder) fail(err error) *MessageBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
@@ -0,0 +1,115 @@
+package messenger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type testTemplate struct {
+	Type    string `json:"template_type"`
+	Text    string `json:"text"`
+	invalid bool
+}
+
+func (t testTemplate) Validate() error {
+	if t.invalid {
+		return ErrTextLengthExceeded
+	}
+	return nil
+}
+
+type testTemplateType string
+
+type testTypedTemplate struct {
+	Text string `json:"text"`
+}
+
+func (testTypedTemplate) Type() testTemplateType {
+	return "button"
+}
+
+func TestMessageBuilderText(t *testing.T) {
+	q, err := NewMessageBuilder().
+		To("123").
+		Text("Hello").
+		TextQuickReply("Yes", "yes").
+		TextQuickReply("No", "no").
+		NotificationType(NotificationTypeSilentPush).
+		Persona("456").
+		Metadata("meta").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Recipient.ID != "123" || q.Message.Text != "Hello" || len(q.Message.QuickReplies) != 2 ||
+		q.NotificationType != NotificationTypeSilentPush || q.PersonaID != "456" || q.Message.Metadata != "meta" {
+		t.Errorf("unexpected query: %+v", q)
+	}
+	if q.MessagingType != MessagingTypeResponse {
+		t.Errorf("expected default messaging type RESPONSE, got %s", q.MessagingType)
+	}
+}
+
+func TestMessageBuilderAttachments(t *testing.T) {
+	q, err := NewMessageBuilder().To("123").Media(AttachmentTypeImage, "https://example.com/a.png").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Message.Attachment.Type != AttachmentTypeImage || string(q.Message.Attachment.Payload) != `{"url":"https://example.com/a.png"}` {
+		t.Errorf("unexpected attachment: %s", q.Message.Attachment.Payload)
+	}
+
+	q, err = NewMessageBuilder().To("123").AttachmentID(AttachmentTypeVideo, "789").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(q.Message.Attachment.Payload) != `{"attachment_id":"789"}` {
+		t.Errorf("unexpected attachment: %s", q.Message.Attachment.Payload)
+	}
+
+	q, err = NewMessageBuilder().To("123").Template(testTemplate{Type: "button", Text: "Hi"}).Tag(MessageTagAccountUpdate).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload testTemplate
+	if err := json.Unmarshal(q.Message.Attachment.Payload, &payload); err != nil || payload.Type != "button" || payload.Text != "Hi" {
+		t.Errorf("unexpected template payload: %s", q.Message.Attachment.Payload)
+	}
+	if q.MessagingType != MessagingTypeMessageTag || q.Tag != MessageTagAccountUpdate {
+		t.Errorf("unexpected messaging type and tag: %s %s", q.MessagingType, q.Tag)
+	}
+
+	q, err = NewMessageBuilder().To("123").Template(testTypedTemplate{Text: "Hi"}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(q.Message.Attachment.Payload) != `{"template_type":"button","text":"Hi"}` {
+		t.Errorf("template_type should be set by the Type method: %s", q.Message.Attachment.Payload)
+	}
+}
+
+func TestMessageBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *MessageBuilder
+	}{
+		{"no recipient", NewMessageBuilder().Text("Hello")},
+		{"no content", NewMessageBuilder().To("123")},
+		{"text and attachment", NewMessageBuilder().To("123").Text("Hello").Media(AttachmentTypeImage, "https://example.com/a.png")},
+		{"empty url", NewMessageBuilder().To("123").Media(AttachmentTypeImage, "")},
+		{"empty attachment id", NewMessageBuilder().To("123").AttachmentID(AttachmentTypeImage, "")},
+		{"invalid template", NewMessageBuilder().To("123").Template(testTemplate{Type: "button", invalid: true})},
+		{"no template type", NewMessageBuilder().To("123").Template(testTemplate{})},
+		{"no tag", NewMessageBuilder().To("123").Text("Hello").MessagingType(MessagingTypeMessageTag)},
+		{"tag without message tag type", NewMessageBuilder().To("123").Text("Hello").Tag(MessageTagHumanAgent).MessagingType(MessagingTypeUpdate)},
+		{"too long text", NewMessageBuilder().To("123").Text(strings.Repeat("a", SendMessageTextLengthLimit+1))},
+	}
+
+	for _, test := range tests {
+		if _, err := test.builder.Build(); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package messenger
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	CommentsPath string = "comments"
+	LikesPath    string = "likes"
+)
+
+// ErrCommentReplyEmpty is returned if the public reply has neither message nor attachment
+var ErrCommentReplyEmpty = errors.New("comment reply has neither message nor attachment")
+
+// CommentReply is a public reply to a comment
+// https://developers.facebook.com/docs/graph-api/reference/object/comments#publish
+type CommentReply struct {
+	Message       string `json:"message,omitempty"`
+	AttachmentID  string `json:"attachment_id,omitempty"`
+	AttachmentURL string `json:"attachment_url,omitempty"`
+}
+
+// CommentResponse represents the response of a comment reply
+type CommentResponse struct {
+	ID string `json:"id"`
+}
+
+// ReplyToComment publicly replies to the comment as the page and returns the id of the reply
+func (c *controller) ReplyToComment(accessToken, commentID string, reply CommentReply) (string, error) {
+	if reply.Message == "" && reply.AttachmentID == "" && reply.AttachmentURL == "" {
+		return "", ErrCommentReplyEmpty
+	}
+
+	var response CommentResponse
+	uri := fmt.Sprintf("%s/%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, commentID, CommentsPath, accessToken)
+	if err := c.doGraphRequest(http.MethodPost, uri, reply, &response); err != nil {
+		return "", errors.Wrapf(err, "ReplyToComment(%s)", commentID)
+	}
+	return response.ID, nil
+}
+
+// HideComment hides the comment from everyone except its author and their friends, or unhides it
+func (c *controller) HideComment(accessToken, commentID string, hidden bool) error {
+	payload := struct {
+		IsHidden bool `json:"is_hidden"`
+	}{hidden}
+
+	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, commentID, accessToken)
+	return errors.Wrapf(c.doSuccessRequest(http.MethodPost, uri, payload), "HideComment(%s, %t)", commentID, hidden)
+}
+
+// DeleteComment deletes the comment
+func (c *controller) DeleteComment(accessToken, commentID string) error {
+	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, commentID, accessToken)
+	return errors.Wrapf(c.doSuccessRequest(http.MethodDelete, uri, nil), "DeleteComment(%s)", commentID)
+}
+
+// LikeComment likes the comment as the page
+func (c *controller) LikeComment(accessToken, commentID string) error {
+	uri := fmt.Sprintf("%s/%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, commentID, LikesPath, accessToken)
+	return errors.Wrapf(c.doSuccessRequest(http.MethodPost, uri, nil), "LikeComment(%s)", commentID)
+}
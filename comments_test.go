@@ -0,0 +1,74 @@
+package messenger
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCommentModeration(t *testing.T) {
+	var requests []string
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/"+GraphAPIVersion)+" "+string(body))
+		if strings.HasSuffix(r.URL.Path, "/comments") {
+			w.Write([]byte(`{"id":"200_301"}`))
+			return
+		}
+		w.Write([]byte(`{"success":true}`))
+	})
+
+	id, err := c.ReplyToComment("token", "200_300", CommentReply{Message: "We sent you a message"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "200_301" {
+		t.Errorf("unexpected reply id: %s", id)
+	}
+	if err := c.HideComment("token", "200_300", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.HideComment("token", "200_300", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LikeComment("token", "200_300"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteComment("token", "200_300"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`POST /200_300/comments {"message":"We sent you a message"}`,
+		`POST /200_300 {"is_hidden":true}`,
+		`POST /200_300 {"is_hidden":false}`,
+		`POST /200_300/likes `,
+		`DELETE /200_300 `,
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+
+	if _, err := c.ReplyToComment("token", "200_300", CommentReply{}); err != ErrCommentReplyEmpty {
+		t.Errorf("empty reply error expected, got %v", err)
+	}
+}
+
+func TestCommentModerationErrors(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.Write([]byte(`{"success":false}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"message":"Requires pages_manage_engagement permission","code":200}}`))
+	})
+
+	if err := c.HideComment("token", "200_300", true); !IsPermissionError(err) {
+		t.Errorf("permission error expected, got %v", err)
+	}
+	if err := c.DeleteComment("token", "200_300"); err == nil {
+		t.Error("error expected for an unsuccessful delete")
+	}
+}
@@ -0,0 +1,596 @@
+// Package config loads messenger page settings from YAML or JSON config files.
+//
+// The file describes the messenger profile in the format of the Messenger Profile API
+// (https://developers.facebook.com/docs/messenger-platform/reference/messenger-profile-api),
+// JSON files can be used as is, YAML files use the same property names:
+//
+//	get_started:
+//	  payload: GET_STARTED
+//	greeting:
+//	  - locale: default
+//	    text: Hello {{user_first_name}}!
+//	  - locale: hu_HU
+//	    text: Szia {{user_first_name}}!
+//	persistent_menu:
+//	  - locale: default
+//	    composer_input_disabled: false
+//	    call_to_actions:
+//	      - type: web_url
+//	        title: Shop
+//	        url: https://${SHOP_HOST}/
+//	        webview_height_ratio: full
+//	      - type: nested
+//	        title: More
+//	        call_to_actions:
+//	          - type: postback
+//	            title: Help
+//	            payload: HELP
+//	ice_breakers:
+//	  - locale: default
+//	    call_to_actions:
+//	      - question: Where is the nearest shop?
+//	        payload: NEAREST_SHOP
+//	whitelisted_domains:
+//	  - https://${SHOP_HOST}
+//
+// Values can refer to environment variables as ${NAME}, or ${NAME:-default} to use a default value
+// when the variable is not set. Referring to an unset variable without a default value is an error.
+//
+// Values are converted to the type of their property, e.g. payload: 123 is the "123" string payload.
+//
+// Unknown properties and values of the wrong type are reported with their line and column in the file,
+// then the loaded settings are validated with Settings.Validate and its errors are reported the same way.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	messenger "github.com/hellowearemito/go-messenger-structs"
+	"gopkg.in/yaml.v3"
+)
+
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// Error is a problem found in a config file
+type Error struct {
+	File   string
+	Line   int
+	Column int
+	// Path locates the invalid property in the JSON form of the settings, e.g. persistent_menu[0].call_to_actions[1].title
+	Path    string
+	Message string
+}
+
+// Error ...
+func (e Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d:%d", e.Line, e.Column)
+	}
+	if e.Path != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Path)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// Errors lists all problems found in a config file
+type Errors []Error
+
+// Error ...
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// LoadSettings reads and validates the settings from the YAML or JSON file
+func LoadSettings(filename string) (messenger.Settings, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return messenger.Settings{}, err
+	}
+	return ParseSettings(filename, data)
+}
+
+// ParseSettings parses and validates the settings from YAML or JSON data, filename is used in the errors only
+func ParseSettings(filename string, data []byte) (messenger.Settings, error) {
+	var settings messenger.Settings
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return settings, Errors{{File: filename, Message: err.Error()}}
+	}
+	if len(doc.Content) == 0 {
+		return settings, Errors{{File: filename, Message: "empty config"}}
+	}
+	root := doc.Content[0]
+
+	var errs Errors
+	interpolate(filename, root, &errs)
+	if len(errs) > 0 {
+		return settings, errs
+	}
+
+	d := decoder{filename: filename}
+	value := d.value(root, reflect.TypeOf(settings), "")
+	if len(d.errs) > 0 {
+		return settings, d.errs
+	}
+	enc, err := json.Marshal(value)
+	if err != nil {
+		return settings, Errors{{File: filename, Message: err.Error()}}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(enc))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&settings); err != nil {
+		typeErr, ok := err.(*json.UnmarshalTypeError)
+		if !ok {
+			return settings, Errors{{File: filename, Message: err.Error()}}
+		}
+		path := valuePath(enc, typeErr.Offset)
+		node := lookup(root, path)
+		return settings, Errors{{
+			File:    filename,
+			Line:    node.Line,
+			Column:  node.Column,
+			Path:    path,
+			Message: fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type),
+		}}
+	}
+
+	if err := settings.Validate(); err != nil {
+		validationErrs, ok := err.(messenger.ValidationErrors)
+		if !ok {
+			return settings, Errors{{File: filename, Message: err.Error()}}
+		}
+		for _, e := range validationErrs {
+			node := lookup(root, e.Path)
+			errs = append(errs, Error{
+				File:    filename,
+				Line:    node.Line,
+				Column:  node.Column,
+				Path:    e.Path,
+				Message: e.Message,
+			})
+		}
+		return settings, errs
+	}
+
+	return settings, nil
+}
+
+// interpolate replaces the environment variable references in the scalar values of the node tree
+func interpolate(filename string, node *yaml.Node, errs *Errors) {
+	if node.Kind == yaml.MappingNode {
+		// keys are not interpolated
+		for i := 1; i < len(node.Content); i += 2 {
+			interpolate(filename, node.Content[i], errs)
+		}
+		return
+	}
+	if node.Kind != yaml.ScalarNode {
+		for _, child := range node.Content {
+			interpolate(filename, child, errs)
+		}
+		return
+	}
+
+	if !strings.Contains(node.Value, "${") {
+		return
+	}
+	node.Value = envPattern.ReplaceAllStringFunc(node.Value, func(ref string) string {
+		match := envPattern.FindStringSubmatch(ref)
+		if value, ok := os.LookupEnv(match[1]); ok {
+			return value
+		}
+		if strings.Contains(ref, ":-") {
+			return match[2]
+		}
+		*errs = append(*errs, Error{
+			File:    filename,
+			Line:    node.Line,
+			Column:  node.Column,
+			Message: fmt.Sprintf("environment variable %s is not set", match[1]),
+		})
+		return ref
+	})
+	// interpolated values are strings, decoder.value converts them if the property isn't a string
+	node.Tag = "!!str"
+}
+
+// listItemTypes lists the item types of the lists decoded by their own UnmarshalJSON,
+// the first one having all the keys of the item is used.
+var listItemTypes = map[reflect.Type][]reflect.Type{
+	reflect.TypeOf(messenger.IceBreakerList{}): {reflect.TypeOf(messenger.IceBreakers{}), reflect.TypeOf(messenger.IceBreaker{})},
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decoder converts the node tree into values that can be marshaled to JSON and decoded into the type of the settings.
+// Scalars are converted to the type of their property, e.g. 2024 is a string if the property is a string,
+// and unknown properties and mismatching types are reported with their line and column.
+type decoder struct {
+	filename string
+	errs     Errors
+}
+
+func (d *decoder) value(node *yaml.Node, t reflect.Type, path string) interface{} {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil
+	}
+
+	if itemTypes, ok := listItemTypes[t]; ok && node.Kind == yaml.SequenceNode {
+		s := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			s[i] = d.value(child, itemType(child, itemTypes), fmt.Sprintf("%s[%d]", path, i))
+		}
+		return s
+	}
+	if t.Kind() == reflect.Interface || reflect.PtrTo(t).Implements(unmarshalerType) {
+		v, err := toValue(node)
+		if err != nil {
+			d.fail(node, path, err.Error())
+		}
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+		fields := jsonFields(t)
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			f, ok := fields.lookup(key.Value)
+			if !ok {
+				d.fail(key, joinPath(path, key.Value), fmt.Sprintf("unknown field %q", key.Value))
+				continue
+			}
+			m[f.name] = d.value(node.Content[i+1], f.typ, joinPath(path, f.name))
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			break
+		}
+		s := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			s[i] = d.value(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+		return s
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			m[key] = d.value(node.Content[i+1], t.Elem(), joinPath(path, key))
+		}
+		return m
+	case reflect.String:
+		if node.Kind == yaml.ScalarNode {
+			return node.Value
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			break
+		}
+		scalar := *node
+		if scalar.Style&(yaml.TaggedStyle|yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			// plain and interpolated values are resolved again, e.g. ${ENABLED} as a bool
+			scalar.Tag = ""
+		}
+		v := reflect.New(t)
+		if scalar.ShortTag() != "!!str" && scalar.Decode(v.Interface()) == nil {
+			return v.Elem().Interface()
+		}
+		d.fail(node, path, fmt.Sprintf("cannot use %s as %s", nodeKind(&scalar), typeName(t)))
+		return nil
+	}
+
+	d.fail(node, path, fmt.Sprintf("cannot use %s as %s", nodeKind(node), typeName(t)))
+	return nil
+}
+
+func (d *decoder) fail(node *yaml.Node, path, message string) {
+	d.errs = append(d.errs, Error{
+		File:    d.filename,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: message,
+	})
+}
+
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+type jsonFieldList []jsonField
+
+// jsonFields returns the properties of the struct type by their JSON names, including the ones of the embedded structs
+func jsonFields(t reflect.Type) jsonFieldList {
+	var fields jsonFieldList
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(f.Type)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: f.Type})
+	}
+	return fields
+}
+
+// lookup returns the field of the key, matching it case-insensitively like encoding/json if needed
+func (fields jsonFieldList) lookup(key string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// itemType returns the first of the types having all the keys of the item
+func itemType(item *yaml.Node, types []reflect.Type) reflect.Type {
+	if item.Kind == yaml.AliasNode {
+		item = item.Alias
+	}
+	for _, t := range types {
+		fields := jsonFields(t)
+		matches := true
+		for i := 0; i+1 < len(item.Content) && item.Kind == yaml.MappingNode; i += 2 {
+			if _, ok := fields.lookup(item.Content[i].Value); !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return t
+		}
+	}
+	return types[0]
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// nodeKind names the kind of the node like the JSON errors do
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!int", "!!float":
+		return "number"
+	case "!!bool":
+		return "bool"
+	case "!!null":
+		return "null"
+	}
+	return "string"
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return t.Kind().String()
+}
+
+// toValue converts the node tree into values that can be marshaled to JSON, without knowing their types
+func toValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v, err := toValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[node.Content[i].Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			v, err := toValue(child)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = v
+		}
+		return s, nil
+	case yaml.AliasNode:
+		return toValue(node.Alias)
+	}
+
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return nil, fmt.Errorf("line %d: %s", node.Line, err)
+	}
+	return v, nil
+}
+
+// lookup returns the node of the path like persistent_menu[0].call_to_actions[1].title,
+// or the deepest existing node on the path if the property is missing.
+func lookup(node *yaml.Node, path string) *yaml.Node {
+	if path == "" {
+		return node
+	}
+
+	for _, part := range strings.Split(path, ".") {
+		key, indexes := parsePathPart(part)
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		if key != "" {
+			child := mappingValue(node, key)
+			if child == nil {
+				return node
+			}
+			node = child
+		}
+
+		for _, i := range indexes {
+			if node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+				return node
+			}
+			node = node.Content[i]
+		}
+	}
+	return node
+}
+
+// valuePath returns the path like persistent_menu[0].call_to_actions[1].title of the JSON value
+// read last before the offset, e.g. the offset of a json.UnmarshalTypeError.
+// UnmarshalTypeError.Field can't be used, it doesn't contain the array indexes.
+func valuePath(data []byte, offset int64) string {
+	type frame struct {
+		array   bool
+		index   int
+		key     string
+		keyNext bool
+	}
+	var stack []*frame
+
+	path := func() string {
+		var b strings.Builder
+		for _, f := range stack {
+			if f.array {
+				fmt.Fprintf(&b, "[%d]", f.index)
+				continue
+			}
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(f.key)
+		}
+		return b.String()
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+
+		if tok == json.Delim('}') || tok == json.Delim(']') {
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && !stack[len(stack)-1].array {
+				stack[len(stack)-1].keyNext = true
+			}
+			continue
+		}
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.array {
+				top.index++
+			} else if top.keyNext {
+				top.key, _ = tok.(string)
+				top.keyNext = false
+				continue
+			}
+		}
+
+		if dec.InputOffset() >= offset {
+			return path()
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{keyNext: true})
+		case json.Delim('['):
+			stack = append(stack, &frame{array: true, index: -1})
+		default:
+			if len(stack) > 0 && !stack[len(stack)-1].array {
+				stack[len(stack)-1].keyNext = true
+			}
+		}
+	}
+}
+
+// parsePathPart splits a path part like call_to_actions[1] into its key and indexes
+func parsePathPart(part string) (string, []int) {
+	var indexes []int
+	key := part
+	if i := strings.Index(part, "["); i >= 0 {
+		key = part[:i]
+		for _, index := range strings.Split(strings.Trim(part[i:], "[]"), "][") {
+			n, err := strconv.Atoi(index)
+			if err != nil {
+				break
+			}
+			indexes = append(indexes, n)
+		}
+	}
+	return key, indexes
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const validYAML = `
+get_started:
+  payload: GET_STARTED
+greeting:
+  - locale: default
+    text: Hello {{user_first_name}}!
+  - locale: hu_HU
+    text: Szia {{user_first_name}}!
+persistent_menu:
+  - locale: default
+    composer_input_disabled: true
+    call_to_actions:
+      - type: web_url
+        title: Shop
+        url: https://${TEST_SHOP_HOST}/
+        webview_height_ratio: full
+      - type: nested
+        title: More
+        call_to_actions:
+          - type: postback
+            title: Help
+            payload: HELP
+ice_breakers:
+  - locale: default
+    call_to_actions:
+      - question: Where is the nearest shop?
+        payload: NEAREST_SHOP
+whitelisted_domains:
+  - https://${TEST_SHOP_HOST}
+  - https://${TEST_UNSET_HOST:-example.org}
+`
+
+func TestParseSettingsYAML(t *testing.T) {
+	os.Setenv("TEST_SHOP_HOST", "shop.example.com")
+	defer os.Unsetenv("TEST_SHOP_HOST")
+
+	s, err := ParseSettings("brand.yaml", []byte(validYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.GetStarted == nil || s.GetStarted.Payload != "GET_STARTED" {
+		t.Errorf("unexpected get started: %v", s.GetStarted)
+	}
+	if len(s.Greetings) != 2 || s.Greetings[1].Text != "Szia {{user_first_name}}!" {
+		t.Errorf("unexpected greetings: %v", s.Greetings)
+	}
+	menu := s.PersistentMenu[0]
+	if !menu.InputDisabled || *menu.CTAs[0].URL != "https://shop.example.com/" || *menu.CTAs[1].CTAs[0].Payload != "HELP" {
+		t.Errorf("unexpected persistent menu: %+v", menu)
+	}
+	if len(s.IceBreakers) != 1 || s.IceBreakers[0].CTAs[0].Payload != "NEAREST_SHOP" {
+		t.Errorf("unexpected ice breakers: %v", s.IceBreakers)
+	}
+	if strings.Join(s.WhitelistedDomains, ",") != "https://shop.example.com,https://example.org" {
+		t.Errorf("unexpected whitelisted domains: %v", s.WhitelistedDomains)
+	}
+}
+
+func TestParseSettingsInterpolatedTypes(t *testing.T) {
+	os.Setenv("TEST_INPUT_DISABLED", "true")
+	defer os.Unsetenv("TEST_INPUT_DISABLED")
+
+	data := `
+get_started:
+  payload: "${TEST_INPUT_DISABLED}"
+persistent_menu:
+  - locale: default
+    composer_input_disabled: ${TEST_INPUT_DISABLED}
+`
+	s, err := ParseSettings("brand.yaml", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetStarted.Payload != "true" {
+		t.Errorf("quoted value should stay a string: %v", s.GetStarted)
+	}
+	if !s.PersistentMenu[0].InputDisabled {
+		t.Errorf("plain value should be resolved as a bool: %+v", s.PersistentMenu[0])
+	}
+}
+
+func TestParseSettingsJSON(t *testing.T) {
+	data := "{\n\t\"get_started\": {\"payload\": \"START\"},\n\t\"greeting\": [\n\t\t{\"locale\": \"default\", \"text\": \"Hello\"}\n\t]\n}\n"
+	s, err := ParseSettings("brand.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetStarted.Payload != "START" || s.Greetings[0].Text != "Hello" {
+		t.Errorf("unexpected settings: %+v", s)
+	}
+
+	data = "{\n\t\"greeting\": [\n\t\t{\"locale\": \"default\", \"text\": \"\"}\n\t]\n}\n"
+	_, err = ParseSettings("brand.json", []byte(data))
+	if err == nil || err.Error() != "brand.json:3:33: greeting[0].text: is required" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseSettingsScalarsAsStrings(t *testing.T) {
+	os.Setenv("TEST_CODE", "42")
+	defer os.Unsetenv("TEST_CODE")
+
+	data := `
+get_started:
+  payload: 123
+greeting:
+  - locale: default
+    text: ${TEST_CODE}
+persistent_menu:
+  - locale: default
+    call_to_actions:
+      - type: postback
+        title: 2024
+        payload: ${TEST_CODE}
+ice_breakers:
+  - question: 2024
+    payload: ${TEST_CODE}
+`
+	s, err := ParseSettings("brand.yaml", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetStarted.Payload != "123" || s.Greetings[0].Text != "42" {
+		t.Errorf("numbers should be decoded as strings: %+v %+v", s.GetStarted, s.Greetings)
+	}
+	cta := s.PersistentMenu[0].CTAs[0]
+	if cta.Title != "2024" || *cta.Payload != "42" {
+		t.Errorf("numbers should be decoded as strings: %+v", cta)
+	}
+	if len(s.IceBreakers) != 1 || s.IceBreakers[0].CTAs[0].Question != "2024" || s.IceBreakers[0].CTAs[0].Payload != "42" {
+		t.Errorf("unexpected ice breakers: %+v", s.IceBreakers)
+	}
+}
+
+func TestParseSettingsErrors(t *testing.T) {
+	data := `
+greeting:
+  - locale: default
+    text: Hello
+  - locale: xx_XX
+    text: Szia
+persistent_menu:
+  - locale: default
+    call_to_actions:
+      - type: postback
+        title: Help
+whitelisted_domains:
+  - http://example.com
+  - https://${TEST_UNSET_HOST}
+`
+	_, err := ParseSettings("brand.yaml", []byte(data))
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 1 || errs[0].Line != 14 || !strings.Contains(errs[0].Message, "TEST_UNSET_HOST") {
+		t.Fatalf("expected unset environment variable error, got %v", err)
+	}
+
+	data = strings.Replace(data, "${TEST_UNSET_HOST}", "example.com", 1)
+	_, err = ParseSettings("brand.yaml", []byte(data))
+	expected := []string{
+		"brand.yaml:5:13: greeting[1].locale: unsupported locale: xx_XX",
+		"brand.yaml:10:9: persistent_menu[0].call_to_actions[0].payload: is required for postback type",
+		"brand.yaml:8:3: persistent_menu: requires get_started to be set",
+		"brand.yaml:13:5: whitelisted_domains[0]: has to be an https url: http://example.com",
+	}
+	if err == nil || err.Error() != strings.Join(expected, "\n") {
+		t.Errorf("unexpected errors:\n%v", err)
+	}
+
+	data = `
+get_started:
+  payload: START
+persistent_menu:
+  - locale: default
+    composer_input_disabled: false
+    call_to_actions:
+      - type: postback
+        title: Help
+        payload: [HELP]
+`
+	_, err = ParseSettings("brand.yaml", []byte(data))
+	if err == nil || err.Error() != "brand.yaml:10:18: persistent_menu[0].call_to_actions[0].payload: cannot use array as string" {
+		t.Errorf("unexpected type error: %v", err)
+	}
+	data = strings.NewReplacer("composer_input_disabled: false", "composer_input_disabled: 'no'", "[HELP]", "HELP").Replace(data)
+	_, err = ParseSettings("brand.yaml", []byte(data))
+	if err == nil || err.Error() != "brand.yaml:6:30: persistent_menu[0].composer_input_disabled: cannot use string as bool" {
+		t.Errorf("unexpected type error: %v", err)
+	}
+
+	data = `
+get_started:
+  payload: START
+greeting:
+  - locale: default
+    txt: Hello
+`
+	_, err = ParseSettings("brand.yaml", []byte(data))
+	if err == nil || err.Error() != `brand.yaml:6:5: greeting[0].txt: unknown field "txt"` {
+		t.Errorf("unexpected unknown field error: %v", err)
+	}
+
+	_, err = ParseSettings("brand.yaml", []byte("get_started: ${TEST_UNSET_HOST:-}\n"))
+	if err == nil || err.Error() != "brand.yaml:1:14: get_started: cannot use string as object" {
+		t.Errorf("empty interpolated value should be kept as a string: %v", err)
+	}
+
+	if _, err := ParseSettings("brand.yaml", []byte("greetings: []")); err == nil {
+		t.Error("unknown property is accepted")
+	}
+	if _, err := ParseSettings("brand.yaml", []byte("greeting: [")); err == nil {
+		t.Error("invalid yaml is accepted")
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	if _, err := LoadSettings("missing.yaml"); err == nil {
+		t.Error("missing file is accepted")
+	}
+
+	f, err := ioutil.TempFile("", "settings*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("get_started:\n  payload: START\n")
+	f.Close()
+
+	s, err := LoadSettings(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetStarted == nil || s.GetStarted.Payload != "START" {
+		t.Errorf("unexpected settings: %+v", s)
+	}
+}
@@ -7,7 +7,12 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -20,11 +25,30 @@ type Controller interface {
 	PassThread(ctx context.Context, targetAppID int64, recipient, metadata, accessToken string) error
 	TakeThread(ctx context.Context, recipient, metadata, accessToken string) error
 	GetProfile(userID string, accessToken string, url string, fields ...Field) (Profile, error)
+	GetProfiles(accessToken string, userIDs []string, fields ...Field) (map[string]Profile, error)
+	Batch(accessToken string, requests []BatchRequest) ([]*BatchResponse, error)
+	List(ctx context.Context, accessToken, path string, pageSize int) *ListIterator
 	UpdatePageSettings(accessToken string, payload json.RawMessage) error
 	DeletePageSettings(accessToken string, payload json.RawMessage) error
+	GetPageSettings(accessToken string, fields ...SettingsField) (Settings, error)
+	SetPageSettings(accessToken string, settings Settings) error
+	DeletePageSettingsFields(accessToken string, fields ...SettingsField) error
+	SetUserPersistentMenu(accessToken, psid string, menus []PersistentMenu) error
+	GetUserPersistentMenu(accessToken, psid string) (UserPersistentMenu, error)
+	DeleteUserPersistentMenu(accessToken, psid string) error
 	SendPrivateReply(objectID, accessToken, messageContent string) (*PrivateReplyResponse, error)
+	SendPrivateReplyMessage(accessToken string, q MessageQuery) (*PrivateReplyResponse, error)
+	ReplyToComment(accessToken, commentID string, reply CommentReply) (string, error)
+	HideComment(accessToken, commentID string, hidden bool) error
+	DeleteComment(accessToken, commentID string) error
+	LikeComment(accessToken, commentID string) error
+	GetPSIDByAccountLinkingToken(accessToken, accountLinkingToken string) (string, error)
+	UnlinkAccount(accessToken, psid string) error
+	UploadAttachment(accessToken string, t AttachmentType, url string) (string, error)
+	UploadAttachmentFromReader(accessToken string, t AttachmentType, filename string, r io.Reader) (string, error)
 
 	CreatePersona(accessToken string, payload json.RawMessage) (*PersonaResponse, error)
+	AddPersona(accessToken string, persona Persona) (*Persona, error)
 	GetPersona(accessToken, personaID string) (*Persona, error)
 	Personas(accessToken string) ([]Persona, error)
 	DeletePersona(accessToken, personaID string) error
@@ -116,15 +140,35 @@ func (c *controller) TakeThread(ctx context.Context, recipient, metadata, access
 
 // GetProfile fetches the recipient's profile from facebook platform
 // Non empty UserID has to be specified in order to receive the information
+// If some of the fields are denied (e.g. locale, timezone or gender without the required permission),
+// the profile is fetched again without them and the dropped fields are listed in Profile.DroppedFields.
 func (c *controller) GetProfile(userID string, accessToken string, url string, fields ...Field) (Profile, error) {
-	profile := Profile{}
-	parameters := "fields="
-	if len(fields) > 0 {
-		parameters += strings.Join(Fields(fields).Stringify(), ",")
-	} else {
-		parameters += "name,first_name,last_name,profile_pic"
+	if len(fields) == 0 {
+		fields = DefaultProfileFields
 	}
 
+	var dropped []Field
+	for {
+		profile, err := c.getProfile(userID, accessToken, url, fields)
+		if err == nil {
+			profile.FetchedFields = fields
+			profile.DroppedFields = dropped
+			return profile, nil
+		}
+
+		remaining, forbidden := withoutForbiddenFields(fields, err)
+		if len(forbidden) == 0 || len(remaining) == 0 {
+			return profile, errors.Wrap(err, "Error occured")
+		}
+		fields = remaining
+		dropped = append(dropped, forbidden...)
+	}
+}
+
+func (c *controller) getProfile(userID string, accessToken string, url string, fields []Field) (Profile, error) {
+	profile := Profile{}
+	parameters := "fields=" + strings.Join(Fields(fields).Stringify(), ",")
+
 	if url == "" {
 		url = fmt.Sprintf("%s/%s/%s?%s&access_token=%s", GraphAPI, c.graphAPIVersion, userID, parameters, accessToken)
 	} else {
@@ -135,22 +179,90 @@ func (c *controller) GetProfile(userID string, accessToken string, url string, f
 		return profile, err
 	}
 	defer resp.Body.Close()
-	read, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return profile, err
+
+	err = decodeResponse(resp, &profile)
+	return profile, err
+}
+
+// GetProfiles fetches the profiles of the users with Graph API batch requests, up to 50 users per request.
+// Profiles which couldn't be fetched are missing from the result, their errors are returned in ProfileErrors.
+// Denied fields are dropped the same way as in GetProfile.
+func (c *controller) GetProfiles(accessToken string, userIDs []string, fields ...Field) (map[string]Profile, error) {
+	if len(fields) == 0 {
+		fields = DefaultProfileFields
 	}
-	if resp.StatusCode != http.StatusOK {
-		er := RawError{}
-		err = json.Unmarshal(read, &er)
-		if err != nil {
-			return profile, errors.Wrap(err, "unmarshal error")
+
+	profiles := make(map[string]Profile, len(userIDs))
+	profileErrs := make(ProfileErrors)
+	if err := c.getProfiles(accessToken, userIDs, fields, nil, profiles, profileErrs); err != nil {
+		return profiles, errors.Wrap(err, "GetProfiles")
+	}
+
+	if len(profileErrs) > 0 {
+		return profiles, profileErrs
+	}
+	return profiles, nil
+}
+
+// getProfiles fetches the profiles in batches, then retries the ones failed because of denied fields without those fields.
+func (c *controller) getProfiles(accessToken string, userIDs []string, fields, dropped []Field, profiles map[string]Profile, profileErrs ProfileErrors) error {
+	type retry struct {
+		userIDs   []string
+		remaining []Field
+		forbidden []Field
+	}
+	retries := make(map[string]*retry)
+	parameters := "fields=" + strings.Join(Fields(fields).Stringify(), ",")
+
+	for len(userIDs) > 0 {
+		n := len(userIDs)
+		if n > BatchRequestLimit {
+			n = BatchRequestLimit
 		}
+		chunk := userIDs[:n]
+		userIDs = userIDs[n:]
 
-		return profile, errors.New("Error occured: " + er.Error.Message)
+		results := make([]Profile, len(chunk))
+		requests := make([]BatchRequest, len(chunk))
+		for i, id := range chunk {
+			requests[i] = NewBatchGet(id+"?"+parameters, &results[i])
+		}
+
+		_, err := c.Batch(accessToken, requests)
+		batchErrs, ok := err.(BatchErrors)
+		if err != nil && !ok {
+			return err
+		}
+
+		for i, id := range chunk {
+			err := batchErrs.ByIndex(i)
+			if err == nil {
+				results[i].FetchedFields = fields
+				results[i].DroppedFields = dropped
+				profiles[id] = results[i]
+				continue
+			}
+
+			remaining, forbidden := withoutForbiddenFields(fields, err)
+			if len(forbidden) == 0 || len(remaining) == 0 {
+				profileErrs[id] = errors.Cause(err)
+				continue
+			}
+			key := strings.Join(Fields(remaining).Stringify(), ",")
+			if retries[key] == nil {
+				retries[key] = &retry{remaining: remaining, forbidden: forbidden}
+			}
+			retries[key].userIDs = append(retries[key].userIDs, id)
+		}
 	}
 
-	err = json.Unmarshal(read, &profile)
-	return profile, err
+	for _, r := range retries {
+		d := append(append([]Field(nil), dropped...), r.forbidden...)
+		if err := c.getProfiles(accessToken, r.userIDs, r.remaining, d, profiles, profileErrs); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DeletePageSettings deletes the messenger page's settings.
@@ -163,6 +275,82 @@ func (c *controller) UpdatePageSettings(accessToken string, payload json.RawMess
 	return c.doUpdateSettingsRequest(http.MethodPost, accessToken, payload)
 }
 
+// GetPageSettings fetches the given fields of the messenger page's settings, all fields of Settings if none is given.
+func (c *controller) GetPageSettings(accessToken string, fields ...SettingsField) (Settings, error) {
+	if len(fields) == 0 {
+		fields = SettingsFields
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = string(f)
+	}
+
+	url := fmt.Sprintf("%s/%s/%s?fields=%s&access_token=%s", GraphAPI, c.graphAPIVersion, MessengerSettingsPath, strings.Join(names, ","), accessToken)
+	var response settingsResponse
+	if err := c.doGraphRequest(http.MethodGet, url, nil, &response); err != nil {
+		return Settings{}, errors.Wrap(err, "GetPageSettings")
+	}
+	if len(response.Data) == 0 {
+		return Settings{}, nil
+	}
+	return response.Data[0], nil
+}
+
+// SetPageSettings updates the fields of the messenger page's settings which are set in settings.
+func (c *controller) SetPageSettings(accessToken string, settings Settings) error {
+	url := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, MessengerSettingsPath, accessToken)
+	return errors.Wrap(c.doGraphRequest(http.MethodPost, url, settings, nil), "SetPageSettings")
+}
+
+// DeletePageSettingsFields deletes the given fields of the messenger page's settings.
+func (c *controller) DeletePageSettingsFields(accessToken string, fields ...SettingsField) error {
+	if len(fields) == 0 {
+		return errors.New("fields are empty")
+	}
+
+	url := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, MessengerSettingsPath, accessToken)
+	return errors.Wrap(c.doGraphRequest(http.MethodDelete, url, deleteSettings{Fields: fields}, nil), "DeletePageSettingsFields")
+}
+
+// SetUserPersistentMenu sets the persistent menu shown to the user instead of the page level one.
+func (c *controller) SetUserPersistentMenu(accessToken, psid string, menus []PersistentMenu) error {
+	settings := CustomUserSettings{
+		PSID:           psid,
+		PersistentMenu: menus,
+	}
+	if err := settings.Validate(); err != nil {
+		return errors.Wrap(err, "SetUserPersistentMenu")
+	}
+
+	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, CustomUserSettingsPath, accessToken)
+	return errors.Wrap(c.doGraphRequest(http.MethodPost, uri, settings, nil), "SetUserPersistentMenu")
+}
+
+// GetUserPersistentMenu fetches the user level and the page level persistent menus of the user.
+func (c *controller) GetUserPersistentMenu(accessToken, psid string) (UserPersistentMenu, error) {
+	var response userPersistentMenuResponse
+	if err := c.doGraphRequest(http.MethodGet, c.userPersistentMenuURL(accessToken, psid), nil, &response); err != nil {
+		return UserPersistentMenu{}, errors.Wrapf(err, "GetUserPersistentMenu(%s)", psid)
+	}
+	if len(response.Data) == 0 {
+		return UserPersistentMenu{}, nil
+	}
+	return response.Data[0], nil
+}
+
+// DeleteUserPersistentMenu deletes the user level persistent menu, the user gets the page level one again.
+func (c *controller) DeleteUserPersistentMenu(accessToken, psid string) error {
+	return errors.Wrapf(c.doGraphRequest(http.MethodDelete, c.userPersistentMenuURL(accessToken, psid), nil, nil), "DeleteUserPersistentMenu(%s)", psid)
+}
+
+func (c *controller) userPersistentMenuURL(accessToken, psid string) string {
+	params := url.Values{}
+	params.Set("psid", psid)
+	params.Set("params", `["`+string(SettingsFieldPersistentMenu)+`"]`)
+	params.Set("access_token", accessToken)
+	return fmt.Sprintf("%s/%s/%s?%s", GraphAPI, c.graphAPIVersion, CustomUserSettingsPath, params.Encode())
+}
+
 // doUpdateSettings sends the update request to facebook.
 func (c *controller) doUpdateSettingsRequest(method string, accessToken string, payload json.RawMessage) error {
 	url := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, MessengerSettingsPath, accessToken)
@@ -200,6 +388,71 @@ func (c *controller) doRequest(method string, url string, body io.Reader) (*http
 	return c.httpClient.Do(req)
 }
 
+// decodeResponse reads the response of graph api and decodes it into v.
+// The graph api Error is returned if the status code is not 200.
+func decodeResponse(resp *http.Response, v interface{}) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "ioutil.ReadAll fail")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		er := RawError{}
+		if err := json.Unmarshal(body, &er); err != nil || er.Error == nil {
+			return errors.Errorf("response.StatusCode != http.StatusOK: %s", string(body))
+		}
+		return *er.Error
+	}
+
+	if v == nil {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal(body, v), "json.Unmarshal(%s)", string(body))
+}
+
+// doGraphRequest sends the payload, if any, encoded as JSON and decodes the response into v, if not nil.
+// The graph api Error is returned if the request failed, errors.Cause returns it.
+func (c *controller) doGraphRequest(method, uri string, payload interface{}, v interface{}) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return errors.Wrapf(err, "json.Marshal(%v)", payload)
+		}
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	resp, err := c.doRequest(method, uri, reader)
+	if err != nil {
+		return errors.Wrap(err, "c.doRequest")
+	}
+	defer resp.Body.Close()
+
+	err = decodeResponse(resp, v)
+	if err != nil && body != nil {
+		return errors.Wrapf(err, "sent: %s", string(body))
+	}
+	return err
+}
+
+// doSuccessRequest sends the request and checks that Graph API reports its success
+func (c *controller) doSuccessRequest(method, uri string, payload interface{}) error {
+	var response struct {
+		Success bool `json:"success"`
+	}
+	if err := c.doGraphRequest(method, uri, payload, &response); err != nil {
+		return err
+	}
+	if !response.Success {
+		return errors.New("graph api reported no success")
+	}
+	return nil
+}
+
 func (c *controller) doThreadRequest(method string, url string, body io.Reader) error {
 	resp, err := c.doRequest(method, url, body)
 	if err != nil {
@@ -219,6 +472,8 @@ func (c *controller) doThreadRequest(method string, url string, body io.Reader)
 	return nil
 }
 
+// SendPrivateReply sends a text reply to the comment or visitor post with the legacy private_replies edge.
+// Deprecated: use SendPrivateReplyMessage, which supports attachments, templates and quick replies.
 func (c *controller) SendPrivateReply(objectID, accessToken, messageContent string) (*PrivateReplyResponse, error) {
 	var response PrivateReplyResponse
 	url := fmt.Sprintf("%s/%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, objectID, PrivateReplyPath, accessToken)
@@ -236,14 +491,31 @@ func (c *controller) SendPrivateReply(objectID, accessToken, messageContent stri
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return &response, errors.Wrapf(err, "SendPrivateReplies/ioutil.ReadAll(%v)", resp.Body)
+	if err := decodeResponse(resp, &response); err != nil {
+		return &response, errors.Wrapf(err, "SendPrivateReplies - sent: %s", string(b))
 	}
 
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return &response, errors.Wrapf(err, "SendPrivateReplies/json.Unmarshal(%v, %v)", body, response)
+	return &response, nil
+}
+
+// SendPrivateReplyMessage sends the message as a private reply with the Send API.
+// The recipient has to be a comment or a visitor post, see MessageBuilder.ToComment and MessageBuilder.ToPost.
+// The message can be any message, including attachments, templates and quick replies.
+func (c *controller) SendPrivateReplyMessage(accessToken string, q MessageQuery) (*PrivateReplyResponse, error) {
+	if err := q.Recipient.validatePrivateReply(); err != nil {
+		return nil, err
+	}
+	if q.Message == nil {
+		return nil, errors.New("SendPrivateReplyMessage: message is empty")
+	}
+	if err := q.Message.Validate(); err != nil {
+		return nil, errors.Wrap(err, "SendPrivateReplyMessage")
+	}
+
+	url := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, MessagesPath, accessToken)
+	var response PrivateReplyResponse
+	if err := c.doGraphRequest(http.MethodPost, url, q, &response); err != nil {
+		return nil, errors.Wrap(err, "SendPrivateReplyMessage")
 	}
 
 	return &response, nil
@@ -251,127 +523,152 @@ func (c *controller) SendPrivateReply(objectID, accessToken, messageContent stri
 
 // CreatePersona creates persona on facebook and retrieves the id of persona.
 func (c *controller) CreatePersona(accessToken string, payload json.RawMessage) (*PersonaResponse, error) {
-	var response PersonaResponse
 	uri := fmt.Sprintf("%s/%s/me/%s?access_token=%s", GraphAPI, c.graphAPIVersion, PersonasPath, accessToken)
 
-	b, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrapf(err, "CreatePersona/json.Marshal(%v)", payload)
+	var response PersonaResponse
+	if err := c.doGraphRequest(http.MethodPost, uri, payload, &response); err != nil {
+		return nil, errors.Wrap(err, "CreatePersona")
 	}
 
-	reader := bytes.NewReader(b)
-	req, err := http.NewRequest(http.MethodPost, uri, reader)
-	if err != nil {
-		return nil, errors.Wrapf(err, "CreatePersona/http.NewRequest(%v, %v, %v)", http.MethodPost, uri, b)
-	}
-	req.Header.Set("Content-Type", "application/json")
+	return &response, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrapf(err, "CreatePersona/c.httpClient.Do(%v)", req)
+// AddPersona validates and creates the persona on facebook and returns it with its id.
+func (c *controller) AddPersona(accessToken string, persona Persona) (*Persona, error) {
+	if err := persona.Validate(); err != nil {
+		return nil, errors.Wrap(err, "AddPersona")
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	persona.ID = ""
+	b, err := json.Marshal(persona)
 	if err != nil {
-		return &response, errors.Wrapf(err, "CreatePersona/ioutil.ReadAll(%v)", resp.Body)
+		return nil, errors.Wrapf(err, "AddPersona/json.Marshal(%v)", persona)
 	}
 
-	err = json.Unmarshal(body, &response)
+	response, err := c.CreatePersona(accessToken, b)
 	if err != nil {
-		return &response, errors.Wrapf(err, "CreatePersona/json.Unmarshal(%v, %v)", body, response)
+		return nil, errors.Wrap(err, "AddPersona")
 	}
 
-	return &response, nil
+	persona.ID = response.ID
+	return &persona, nil
 }
 
 // GetPersona retrieves the persona by the given id.
 func (c *controller) GetPersona(accessToken, personaID string) (*Persona, error) {
-	var response Persona
 	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, personaID, accessToken)
 
-	req, err := http.NewRequest(http.MethodGet, uri, nil)
-	if err != nil {
-		return nil, errors.Wrapf(err, "GetPersona/http.NewRequest(%v, %v)", http.MethodGet, uri)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrapf(err, "GetPersona/c.httpClient.Do(%v)", req)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return &response, errors.Wrapf(err, "GetPersona/ioutil.ReadAll(%v)", resp.Body)
+	var response Persona
+	if err := c.doGraphRequest(http.MethodGet, uri, nil, &response); err != nil {
+		return nil, errors.Wrapf(err, "GetPersona(%s)", personaID)
 	}
-
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return &response, errors.Wrapf(err, "GetPersona/json.Unmarshal(%v, %v)", body, response)
+	if response.ID == "" {
+		response.ID = personaID
 	}
 
 	return &response, nil
 }
 
-// Personas retrieves the personas for the given access token of page.
+// Personas retrieves all personas of the page, following the paging of the list.
 func (c *controller) Personas(accessToken string) ([]Persona, error) {
-	var response ListOfPersonaResponse
-	uri := fmt.Sprintf("%s/%s/me/%s?access_token=%s", GraphAPI, c.graphAPIVersion, PersonasPath, accessToken)
-
-	req, err := http.NewRequest(http.MethodGet, uri, nil)
-	if err != nil {
-		return response.Data, errors.Wrapf(err, "Personas/http.NewRequest(%v, %v)", http.MethodGet, uri)
-	}
+	var personas []Persona
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return response.Data, errors.Wrapf(err, "Personas/c.httpClient.Do(%v)", req)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return response.Data, errors.Wrapf(err, "Personas/ioutil.ReadAll(%v)", resp.Body)
+	it := c.List(context.Background(), accessToken, "me/"+PersonasPath, 0)
+	for it.Next() {
+		var p Persona
+		if err := it.Decode(&p); err != nil {
+			return personas, errors.Wrap(err, "Personas")
+		}
+		personas = append(personas, p)
 	}
-
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return response.Data, errors.Wrapf(err, "Personas/json.Unmarshal(%v, %v)", body, response)
+	if err := it.Err(); err != nil {
+		return personas, errors.Wrap(err, "Personas")
 	}
 
-	return response.Data, nil
+	return personas, nil
 }
 
 // DeletePersona removes the persona by the given id.
 func (c *controller) DeletePersona(accessToken, personaID string) error {
-	var response DeletePersonaResponse
 	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, personaID, accessToken)
 
-	req, err := http.NewRequest(http.MethodDelete, uri, nil)
-	if err != nil {
-		return errors.Wrapf(err, "DeletePersona/http.NewRequest(%v, %v)", http.MethodDelete, uri)
+	return errors.Wrapf(c.doSuccessRequest(http.MethodDelete, uri, nil), "DeletePersona(%s)", personaID)
+}
+
+// UploadAttachment uploads the media from the given url with the Attachment Upload API
+// and returns the attachment id which can be reused in messages and media templates.
+func (c *controller) UploadAttachment(accessToken string, t AttachmentType, url string) (string, error) {
+	if url == "" {
+		return "", errors.New("url is empty")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	var upload AttachmentUpload
+	upload.Message.Attachment = UploadedAttachment{
+		Type:    t,
+		Payload: Resource{URL: url, Reusable: true},
+	}
+	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, MessageAttachmentsPath, accessToken)
+	var response ReusableAttachment
+	if err := c.doGraphRequest(http.MethodPost, uri, upload, &response); err != nil {
+		return "", errors.Wrap(err, "UploadAttachment")
+	}
+	return response.AttachmentID, nil
+}
+
+// UploadAttachmentFromReader uploads the media read from r as a multipart form with the Attachment Upload API
+// and returns the attachment id which can be reused in messages and media templates.
+// The content type of the file is detected from the extension of filename.
+func (c *controller) UploadAttachmentFromReader(accessToken string, t AttachmentType, filename string, r io.Reader) (string, error) {
+	var upload AttachmentUpload
+	upload.Message.Attachment = UploadedAttachment{
+		Type:    t,
+		Payload: Resource{Reusable: true},
+	}
+	message, err := json.Marshal(upload.Message)
 	if err != nil {
-		return errors.Wrapf(err, "DeletePersona/c.httpClient.Do(%v)", req)
+		return "", errors.Wrapf(err, "UploadAttachmentFromReader/json.Marshal(%v)", upload.Message)
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("message", string(message)); err != nil {
+		return "", errors.Wrap(err, "UploadAttachmentFromReader/w.WriteField")
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="filedata"; filename=%q`, filepath.Base(filename)))
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
 	if err != nil {
-		return errors.Wrapf(err, "DeletePersona/ioutil.ReadAll(%v)", resp.Body)
+		return "", errors.Wrap(err, "UploadAttachmentFromReader/w.CreatePart")
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return "", errors.Wrap(err, "UploadAttachmentFromReader/io.Copy")
+	}
+	if err := w.Close(); err != nil {
+		return "", errors.Wrap(err, "UploadAttachmentFromReader/w.Close")
 	}
 
-	err = json.Unmarshal(body, &response)
+	uri := fmt.Sprintf("%s/%s/%s?access_token=%s", GraphAPI, c.graphAPIVersion, MessageAttachmentsPath, accessToken)
+	req, err := http.NewRequest(http.MethodPost, uri, body)
 	if err != nil {
-		return errors.Wrapf(err, "DeletePersona/json.Unmarshal(%v, %v)", body, response)
+		return "", errors.Wrapf(err, "UploadAttachmentFromReader/http.NewRequest(%v, %v)", http.MethodPost, uri)
 	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
 
-	if !response.Success {
-		return fmt.Errorf("DeletePersona/NotSuccessDelete(%v)", personaID)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "UploadAttachmentFromReader/c.httpClient.Do(%v)", req)
 	}
+	defer resp.Body.Close()
 
-	return nil
+	var response ReusableAttachment
+	if err := decodeResponse(resp, &response); err != nil {
+		return "", errors.Wrapf(err, "UploadAttachmentFromReader(%s)", filename)
+	}
+	return response.AttachmentID, nil
 }
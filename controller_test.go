@@ -0,0 +1,296 @@
+package messenger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// newTestController returns a controller sending its requests to a test server running the handler.
+func newTestController(t *testing.T, handler http.HandlerFunc) Controller {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	graphAPI := GraphAPI
+	GraphAPI = server.URL
+	t.Cleanup(func() { GraphAPI = graphAPI })
+
+	c := NewController()
+	c.SetHTTPClient(server.Client())
+	return c
+}
+
+func TestUploadAttachment(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+GraphAPIVersion+"/"+MessageAttachmentsPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var upload AttachmentUpload
+		if err := json.NewDecoder(r.Body).Decode(&upload); err != nil {
+			t.Error(err)
+			return
+		}
+		attachment := upload.Message.Attachment
+		if attachment.Type != AttachmentTypeImage || attachment.Payload.URL != "https://example.com/a.png" || !attachment.Payload.Reusable {
+			t.Errorf("unexpected upload: %+v", upload)
+		}
+		w.Write([]byte(`{"attachment_id":"1857777774821032"}`))
+	})
+
+	id, err := c.UploadAttachment("token", AttachmentTypeImage, "https://example.com/a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "1857777774821032" {
+		t.Errorf("unexpected attachment id: %s", id)
+	}
+}
+
+func TestUploadAttachmentFromReader(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.FormValue("message") != `{"attachment":{"type":"image","payload":{"is_reusable":true}}}` {
+			t.Errorf("unexpected message: %s", r.FormValue("message"))
+		}
+		file, header, err := r.FormFile("filedata")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		content, _ := ioutil.ReadAll(file)
+		if header.Filename != "logo.png" || header.Header.Get("Content-Type") != "image/png" || string(content) != "PNG" {
+			t.Errorf("unexpected file: %s %s %s", header.Filename, header.Header.Get("Content-Type"), content)
+		}
+		w.Write([]byte(`{"attachment_id":"42"}`))
+	})
+
+	id, err := c.UploadAttachmentFromReader("token", AttachmentTypeImage, "/tmp/logo.png", strings.NewReader("PNG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "42" {
+		t.Errorf("unexpected attachment id: %s", id)
+	}
+}
+
+func TestUploadAttachmentError(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"Invalid URL","type":"OAuthException","code":100}}`))
+	})
+
+	_, err := c.UploadAttachment("token", AttachmentTypeImage, "https://example.com/missing.png")
+	graphErr, ok := errors.Cause(err).(Error)
+	if !ok || graphErr.Code != 100 {
+		t.Errorf("expected graph api error, got %v", err)
+	}
+}
+
+func TestPageSettings(t *testing.T) {
+	var requests []string
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Query().Get("fields")+" "+string(body))
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"data":[{"get_started":{"payload":"start"},"greeting":[{"locale":"default","text":"Hello"}]}]}`))
+			return
+		}
+		w.Write([]byte(`{"result":"success"}`))
+	})
+
+	settings, err := c.GetPageSettings("token", SettingsFieldGetStarted, SettingsFieldGreeting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.GetStarted == nil || settings.GetStarted.Payload != "start" || len(settings.Greetings) != 1 {
+		t.Errorf("unexpected settings: %+v", settings)
+	}
+
+	settings = Settings{}
+	settings.SetGetStarted("start")
+	if err := c.SetPageSettings("token", settings); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeletePageSettingsFields("token", SettingsFieldGreeting); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`GET get_started,greeting `,
+		`POST  {"get_started":{"payload":"start"}}`,
+		`DELETE  {"fields":["greeting"]}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+}
+
+func TestPageSettingsError(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"message":"(#200) Requires pages_messaging permission","code":200}}`))
+	})
+
+	settings := Settings{}
+	settings.SetGetStarted("start")
+	if err := c.SetPageSettings("token", settings); !IsPermissionError(err) {
+		t.Errorf("permission error expected, got %v", err)
+	}
+	if err := c.DeletePageSettingsFields("token", SettingsFieldGreeting); !IsPermissionError(err) {
+		t.Errorf("permission error expected, got %v", err)
+	}
+}
+
+func TestUserPersistentMenu(t *testing.T) {
+	var requests []string
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Query().Get("psid")+" "+r.URL.Query().Get("params")+" "+string(body))
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"data":[{"user_level_persistent_menu":[{"locale":"default","composer_input_disabled":false,"call_to_actions":[{"type":"postback","title":"My orders","payload":"orders"}]}]}]}`))
+			return
+		}
+		w.Write([]byte(`{"result":"success"}`))
+	})
+
+	payload := "orders"
+	menu := PersistentMenu{Locale: DefaultLocale}
+	menu.AddCTA(CTA{Title: "My orders", Type: CTATypePostback, Payload: &payload})
+
+	if err := c.SetUserPersistentMenu("token", "123", []PersistentMenu{menu}); err != nil {
+		t.Fatal(err)
+	}
+	menus, err := c.GetUserPersistentMenu("token", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(menus.UserLevel) != 1 || menus.UserLevel[0].CTAs[0].Title != "My orders" {
+		t.Errorf("unexpected menus: %+v", menus)
+	}
+	if err := c.DeleteUserPersistentMenu("token", "123"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`POST   {"psid":"123","persistent_menu":[{"locale":"default","composer_input_disabled":false,"call_to_actions":[{"title":"My orders","type":"postback","payload":"orders"}]}]}`,
+		`GET 123 ["persistent_menu"] `,
+		`DELETE 123 ["persistent_menu"] `,
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+
+	if err := c.SetUserPersistentMenu("token", "123", []PersistentMenu{{Locale: DefaultLocale, CTAs: []CTA{{Title: "Broken", Type: CTATypePostback}}}}); err == nil {
+		t.Error("invalid menu is accepted")
+	}
+	if len(requests) != 3 {
+		t.Error("invalid menu is sent")
+	}
+}
+
+func TestGetProfiles(t *testing.T) {
+	var batches int
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		batches++
+		if r.Method != http.MethodPost || r.URL.Path != "/"+GraphAPIVersion+"/" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.FormValue("access_token") != "token" {
+			t.Errorf("unexpected access token: %s", r.FormValue("access_token"))
+		}
+		var requests []BatchRequest
+		if err := json.Unmarshal([]byte(r.FormValue("batch")), &requests); err != nil {
+			t.Error(err)
+			return
+		}
+
+		responses := make([]BatchResponse, len(requests))
+		for i, req := range requests {
+			switch {
+			case strings.HasPrefix(req.RelativeURL, "0?"):
+				responses[i] = BatchResponse{Code: 400, Body: `{"error":{"message":"Unsupported get request","code":100}}`}
+			case strings.HasPrefix(req.RelativeURL, "1?fields=first_name,locale"):
+				responses[i] = BatchResponse{Code: 403, Body: `{"error":{"message":"Requires user_locale permission","code":200}}`}
+			case strings.HasSuffix(req.RelativeURL, "?fields=first_name,locale"):
+				responses[i] = BatchResponse{Code: 200, Body: `{"first_name":"User","locale":"en_US"}`}
+			case req.RelativeURL == "1?fields=first_name":
+				responses[i] = BatchResponse{Code: 200, Body: `{"first_name":"Private"}`}
+			default:
+				t.Errorf("unexpected relative url: %s", req.RelativeURL)
+			}
+		}
+		json.NewEncoder(w).Encode(responses)
+	})
+
+	ids := make([]string, 60)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+	profiles, err := c.GetProfiles("token", ids, FirstName, Locale)
+	if batches != 3 {
+		t.Errorf("60 profiles should be fetched in 2 batches and a retry, got %d", batches)
+	}
+	profileErrs, ok := err.(ProfileErrors)
+	if !ok || len(profileErrs) != 1 || profileErrs["0"] == nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if p := profiles["1"]; p.FirstName != "Private" || !p.Dropped(Locale) || len(p.DroppedFields) != 1 {
+		t.Errorf("profile should be fetched without the denied field: %+v", p)
+	}
+	if len(profiles) != 59 || profiles["59"].Locale != "en_US" {
+		t.Errorf("unexpected profiles: %v", profiles)
+	}
+}
+
+func TestGetProfileDropsDeniedFields(t *testing.T) {
+	var requested []string
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		fields := r.URL.Query().Get("fields")
+		requested = append(requested, fields)
+		switch fields {
+		case "first_name,gender,timezone":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"(#100) Tried accessing nonexisting field (gender)","code":100}}`))
+		case "first_name,timezone":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"message":"(#230) Requires pages_user_timezone permission","code":230}}`))
+		case "first_name":
+			w.Write([]byte(`{"first_name":"User"}`))
+		}
+	})
+
+	profile, err := c.GetProfile("1", "token", "", FirstName, Gender, Timezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 3 {
+		t.Errorf("unexpected requests: %v", requested)
+	}
+	if profile.FirstName != "User" || !profile.Dropped(Gender) || !profile.Dropped(Timezone) || profile.Dropped(FirstName) {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+	if profile.Location() != nil {
+		t.Error("location of a dropped timezone should be nil")
+	}
+}
+
+func TestGetProfileError(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"message":"Permission denied","code":10}}`))
+	})
+
+	_, err := c.GetProfile("1", "token", "")
+	if !IsPermissionError(err) {
+		t.Errorf("permission error expected, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package messenger
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// DefaultDeduplicatorSize is the number of keys remembered by a Deduplicator if no size is given
+const DefaultDeduplicatorSize = 10000
+
+// DedupKey returns a key identifying the event, the same for the redeliveries of the event.
+// Messages are identified by their mid, reactions and edits by the mid of the message they change,
+// optins by their sender, user ref, tokens and timestamp, the other events by their type, sender and timestamp.
+func (e Entry) DedupKey() string {
+	switch {
+	case e.Message != nil && e.Message.IsDeleted:
+		return "unsend:" + e.Message.ID
+	case e.Message != nil && e.Message.IsEcho:
+		return "echo:" + e.Message.ID
+	case e.Message != nil:
+		return "message:" + e.Message.ID
+	case e.Reaction != nil:
+		return fmt.Sprintf("reaction:%s:%s:%s:%s:%d", e.Reaction.MID, e.Sender.ID, e.Reaction.Action, e.Reaction.Reaction, e.Timestamp)
+	case e.MessageEdit != nil:
+		return "message_edit:" + e.MessageEdit.MID + ":" + strconv.Itoa(e.MessageEdit.NumEdit)
+	case e.Delivery != nil:
+		return fmt.Sprintf("delivery:%s:%d", e.Sender.ID, e.Delivery.Watermark)
+	case e.Read != nil:
+		return fmt.Sprintf("read:%s:%d", e.Sender.ID, e.Read.Watermark)
+	case e.Optin != nil:
+		return fmt.Sprintf("optin:%s:%s:%s:%s:%d", e.Sender.ID, e.Optin.UserRef, e.Optin.OneTimeNotifToken, e.Optin.NotificationMessagesToken, e.Timestamp)
+	}
+	return fmt.Sprintf("%s:%s:%s:%d", e.eventType(), e.Sender.ID, e.Recipient.ID, e.Timestamp)
+}
+
+func (e Entry) eventType() string {
+	switch {
+	case e.Postback != nil:
+		return "postback"
+	case e.Referral != nil:
+		return "referral"
+	case e.AccountLinking != nil:
+		return "account_linking"
+	case e.AppRoles != nil:
+		return "app_roles"
+	case e.PassThreadControl != nil:
+		return "pass_thread_control"
+	case e.TakeThreadControl != nil:
+		return "take_thread_control"
+	case e.RequestThreadControl != nil:
+		return "request_thread_control"
+	}
+	return "unknown"
+}
+
+// Deduplicator remembers the last keys it has seen, the oldest one is forgotten first
+type Deduplicator struct {
+	size int
+
+	mu   sync.Mutex
+	lru  *list.List
+	keys map[string]*list.Element
+}
+
+// NewDeduplicator returns a Deduplicator remembering the last size keys
+func NewDeduplicator(size int) *Deduplicator {
+	if size <= 0 {
+		size = DefaultDeduplicatorSize
+	}
+	return &Deduplicator{
+		size: size,
+		lru:  list.New(),
+		keys: make(map[string]*list.Element),
+	}
+}
+
+// Seen reports whether the key has been marked before
+func (d *Deduplicator) Seen(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.keys[key]
+	if ok {
+		d.lru.MoveToFront(e)
+	}
+	return ok
+}
+
+// Mark remembers the key, e.g. after its event has been handled successfully
+func (d *Deduplicator) Mark(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, ok := d.keys[key]; ok {
+		d.lru.MoveToFront(e)
+		return
+	}
+
+	d.keys[key] = d.lru.PushFront(key)
+	if d.lru.Len() > d.size {
+		oldest := d.lru.Back()
+		d.lru.Remove(oldest)
+		delete(d.keys, oldest.Value.(string))
+	}
+}
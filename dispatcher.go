@@ -0,0 +1,170 @@
+package messenger
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Dispatcher calls the typed handler of every event of a webhook payload.
+// Events whose handler is nil are skipped. The handlers of messaging events receive the whole Entry too,
+// e.g. for the sender or the NLP entities.
+type Dispatcher struct {
+	Message              func(e Entry, m MessageEcho) error
+	Echo                 func(e Entry, m MessageEcho) error
+	Reaction             func(e Entry, r Reaction) error
+	MessageEdit          func(e Entry, m MessageEdit) error
+	Delivery             func(e Entry, d Delivery) error
+	Read                 func(e Entry, r Read) error
+	Postback             func(e Entry, p Postback) error
+	Optin                func(e Entry, o Optin) error
+	Referral             func(e Entry, r Referral) error
+	AccountLinking       func(e Entry, a AccountLinking) error
+	AppRoles             func(e Entry, roles AppRolesCallback) error
+	PassThreadControl    func(e Entry, p PassThreadControlCallback) error
+	TakeThreadControl    func(e Entry, t TakeThreadControlCallback) error
+	RequestThreadControl func(e Entry, r RequestThreadControlCallback) error
+	// Unsend receives the messages deleted by the user, their is_deleted flag is set
+	Unsend func(e Entry, m MessageEcho) error
+	// Standby receives the events of the threads owned by another app
+	Standby func(e Entry) error
+
+	// FeedChange receives the comments, posts and reactions of the page feed, page is the page entry
+	FeedChange func(page Event, change FeedChange) error
+	// Change receives the changes of the other subscribed fields
+	Change func(page Event, change Change) error
+
+	// Seen, if set, is called with the Entry.DedupKey of every messaging and standby event,
+	// the event is skipped if it returns true. Mark, if set, is called with the key once the event's handler succeeded,
+	// so failed events are handled again when they are redelivered. Use Deduplicator.Seen and Deduplicator.Mark.
+	Seen func(key string) bool
+	Mark func(key string)
+}
+
+// Dispatch calls the handlers of the events in their order, it stops at the first error.
+func (d *Dispatcher) Dispatch(event UpstreamEvent) error {
+	for _, me := range event.Entries {
+		if me == nil {
+			continue
+		}
+		for _, e := range me.Messaging {
+			if d.seen(e) {
+				continue
+			}
+			if err := d.dispatchEntry(e); err != nil {
+				return err
+			}
+			d.mark(e)
+		}
+		if d.Standby != nil {
+			for _, e := range me.Standby {
+				if d.seen(e) {
+					continue
+				}
+				if err := d.Standby(e); err != nil {
+					return errors.Wrap(err, "Dispatch/Standby")
+				}
+				d.mark(e)
+			}
+		}
+		for _, c := range me.Changes {
+			if err := d.dispatchChange(me.Event, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) dispatchEntry(e Entry) error {
+	var err error
+	switch {
+	case e.Message != nil && e.Message.IsDeleted:
+		if d.Unsend != nil {
+			err = errors.Wrap(d.Unsend(e, *e.Message), "Dispatch/Unsend")
+		}
+	case e.Message != nil && e.Message.IsEcho:
+		if d.Echo != nil {
+			err = errors.Wrap(d.Echo(e, *e.Message), "Dispatch/Echo")
+		}
+	case e.Message != nil:
+		if d.Message != nil {
+			err = errors.Wrap(d.Message(e, *e.Message), "Dispatch/Message")
+		}
+	case e.Reaction != nil:
+		if d.Reaction != nil {
+			err = errors.Wrap(d.Reaction(e, *e.Reaction), "Dispatch/Reaction")
+		}
+	case e.MessageEdit != nil:
+		if d.MessageEdit != nil {
+			err = errors.Wrap(d.MessageEdit(e, *e.MessageEdit), "Dispatch/MessageEdit")
+		}
+	case e.Delivery != nil:
+		if d.Delivery != nil {
+			err = errors.Wrap(d.Delivery(e, *e.Delivery), "Dispatch/Delivery")
+		}
+	case e.Read != nil:
+		if d.Read != nil {
+			err = errors.Wrap(d.Read(e, *e.Read), "Dispatch/Read")
+		}
+	case e.Postback != nil:
+		if d.Postback != nil {
+			err = errors.Wrap(d.Postback(e, *e.Postback), "Dispatch/Postback")
+		}
+	case e.Optin != nil:
+		if d.Optin != nil {
+			err = errors.Wrap(d.Optin(e, *e.Optin), "Dispatch/Optin")
+		}
+	case e.Referral != nil:
+		if d.Referral != nil {
+			err = errors.Wrap(d.Referral(e, *e.Referral), "Dispatch/Referral")
+		}
+	case e.AccountLinking != nil:
+		if d.AccountLinking != nil {
+			err = errors.Wrap(d.AccountLinking(e, *e.AccountLinking), "Dispatch/AccountLinking")
+		}
+	case e.AppRoles != nil:
+		if d.AppRoles != nil {
+			err = errors.Wrap(d.AppRoles(e, *e.AppRoles), "Dispatch/AppRoles")
+		}
+	case e.PassThreadControl != nil:
+		if d.PassThreadControl != nil {
+			err = errors.Wrap(d.PassThreadControl(e, *e.PassThreadControl), "Dispatch/PassThreadControl")
+		}
+	case e.TakeThreadControl != nil:
+		if d.TakeThreadControl != nil {
+			err = errors.Wrap(d.TakeThreadControl(e, *e.TakeThreadControl), "Dispatch/TakeThreadControl")
+		}
+	case e.RequestThreadControl != nil:
+		if d.RequestThreadControl != nil {
+			err = errors.Wrap(d.RequestThreadControl(e, *e.RequestThreadControl), "Dispatch/RequestThreadControl")
+		}
+	}
+	return err
+}
+
+func (d *Dispatcher) seen(e Entry) bool {
+	return d.Seen != nil && d.Seen(e.DedupKey())
+}
+
+func (d *Dispatcher) mark(e Entry) {
+	if d.Mark != nil {
+		d.Mark(e.DedupKey())
+	}
+}
+
+func (d *Dispatcher) dispatchChange(page Event, c Change) error {
+	feed, ok, err := c.Feed()
+	if err != nil {
+		return errors.Wrap(err, "Dispatch")
+	}
+	if ok {
+		if d.FeedChange == nil {
+			return nil
+		}
+		return errors.Wrap(d.FeedChange(page, *feed), "Dispatch/FeedChange")
+	}
+
+	if d.Change == nil {
+		return nil
+	}
+	return errors.Wrap(d.Change(page, c), "Dispatch/Change")
+}
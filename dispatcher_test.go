@@ -0,0 +1,233 @@
+package messenger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDispatcher(t *testing.T) {
+	payload := `{
+		"object": "page",
+		"entry": [{
+			"id": "100",
+			"time": 1520383571,
+			"messaging": [
+				{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 1, "message": {"mid": "m_1", "text": "hi"}},
+				{"sender": {"id": "100"}, "recipient": {"id": "400"}, "timestamp": 2, "message": {"mid": "m_2", "text": "hello", "is_echo": true}},
+				{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 3, "postback": {"payload": "START"}},
+				{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 4, "read": {"watermark": 3}}
+			],
+			"standby": [
+				{"sender": {"id": "401"}, "recipient": {"id": "100"}, "timestamp": 5, "message": {"mid": "m_3", "text": "other app"}}
+			]
+		}, {
+			"id": "100",
+			"time": 1520383572,
+			"changes": [
+				{"field": "feed", "value": {"item": "comment", "verb": "add", "comment_id": "200_300", "from": {"id": "400"}}},
+				{"field": "mention", "value": {"post_id": "100_600"}}
+			]
+		}]
+	}`
+	var event UpstreamEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []string
+	d := Dispatcher{
+		Message: func(e Entry, m MessageEcho) error {
+			calls = append(calls, "message "+e.Sender.ID+" "+m.Text)
+			return nil
+		},
+		Echo: func(e Entry, m MessageEcho) error {
+			calls = append(calls, "echo "+m.Text)
+			return nil
+		},
+		Postback: func(e Entry, p Postback) error {
+			calls = append(calls, "postback "+p.Payload)
+			return nil
+		},
+		Standby: func(e Entry) error {
+			calls = append(calls, "standby "+e.Sender.ID)
+			return nil
+		},
+		FeedChange: func(page Event, c FeedChange) error {
+			calls = append(calls, "feed "+page.ID+" "+c.CommentID)
+			return nil
+		},
+		Change: func(page Event, c Change) error {
+			calls = append(calls, "change "+c.Field)
+			return nil
+		},
+	}
+	if err := d.Dispatch(event); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"message 400 hi", "echo hello", "postback START", "standby 401", "feed 100 200_300", "change mention"}
+	if len(calls) != len(expected) {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: %s, expected %s", i, calls[i], expected[i])
+		}
+	}
+
+	handlerErr := errors.New("failed")
+	d.Postback = func(e Entry, p Postback) error { return handlerErr }
+	calls = nil
+	if err := d.Dispatch(event); err == nil || len(calls) != 2 {
+		t.Errorf("dispatch should stop at the first error: %v, %v", err, calls)
+	}
+}
+
+const reactionsPayload = `{
+	"object": "page",
+	"entry": [{
+		"id": "100",
+		"time": 1520383571,
+		"messaging": [
+			{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 1, "message": {"mid": "m_2", "text": "yes", "reply_to": {"mid": "m_1"}}},
+			{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 2, "reaction": {"reaction": "love", "emoji": "❤️", "action": "react", "mid": "m_1"}},
+			{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 3, "message_edit": {"mid": "m_2", "text": "yes please", "num_edit": 1}},
+			{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 4, "message": {"mid": "m_2", "is_deleted": true}},
+			{"sender": {"id": "400"}, "recipient": {"id": "100"}, "timestamp": 1, "message": {"mid": "m_2", "text": "yes", "reply_to": {"mid": "m_1"}}}
+		]
+	}]
+}`
+
+func TestDispatcherReactionsEditsUnsends(t *testing.T) {
+	var event UpstreamEvent
+	if err := json.Unmarshal([]byte(reactionsPayload), &event); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []string
+	d := Dispatcher{
+		Message: func(e Entry, m MessageEcho) error {
+			calls = append(calls, "message "+m.ID+" reply to "+m.ReplyTo.MID)
+			return nil
+		},
+		Reaction: func(e Entry, r Reaction) error {
+			calls = append(calls, "reaction "+string(r.Action)+" "+r.Reaction+" "+r.Emoji+" "+r.MID)
+			return nil
+		},
+		MessageEdit: func(e Entry, m MessageEdit) error {
+			calls = append(calls, "edit "+m.MID+" "+m.Text)
+			return nil
+		},
+		Unsend: func(e Entry, m MessageEcho) error {
+			calls = append(calls, "unsend "+m.ID)
+			return nil
+		},
+	}
+	dedup := NewDeduplicator(10)
+	d.Seen, d.Mark = dedup.Seen, dedup.Mark
+	if err := d.Dispatch(event); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"message m_2 reply to m_1",
+		"reaction react love ❤️ m_1",
+		"edit m_2 yes please",
+		"unsend m_2",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("unexpected calls, the redelivered message should be skipped: %v", calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: %s, expected %s", i, calls[i], expected[i])
+		}
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	var event UpstreamEvent
+	if err := json.Unmarshal([]byte(reactionsPayload), &event); err != nil {
+		t.Fatal(err)
+	}
+	messaging := event.Entries[0].Messaging
+
+	expected := []string{
+		"message:m_2",
+		"reaction:m_1:400:react:love:2",
+		"message_edit:m_2:1",
+		"unsend:m_2",
+		"message:m_2",
+	}
+	for i, e := range messaging {
+		if key := e.DedupKey(); key != expected[i] {
+			t.Errorf("DedupKey of event %d = %s, expected %s", i, key, expected[i])
+		}
+	}
+
+	var postback Entry
+	postback.Sender.ID = "400"
+	postback.Recipient.ID = "100"
+	postback.Timestamp = 5
+	postback.Postback = &Postback{Payload: "START"}
+	if key := postback.DedupKey(); key != "postback:400:100:5" {
+		t.Errorf("unexpected postback key: %s", key)
+	}
+}
+
+func TestDispatcherRetriesFailedEvents(t *testing.T) {
+	var event UpstreamEvent
+	if err := json.Unmarshal([]byte(reactionsPayload), &event); err != nil {
+		t.Fatal(err)
+	}
+
+	dedup := NewDeduplicator(10)
+	fail := true
+	var calls []string
+	d := Dispatcher{
+		Message: func(e Entry, m MessageEcho) error {
+			calls = append(calls, "message "+m.ID)
+			if fail {
+				return errors.New("temporary error")
+			}
+			return nil
+		},
+		Seen: dedup.Seen,
+		Mark: dedup.Mark,
+	}
+
+	if err := d.Dispatch(event); err == nil {
+		t.Fatal("expected the handler's error")
+	}
+	if dedup.Seen("message:m_2") {
+		t.Error("the failed message must not be marked as seen")
+	}
+
+	fail = false
+	if err := d.Dispatch(event); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[1] != "message m_2" {
+		t.Errorf("the redelivered message should be handled again: %v", calls)
+	}
+	if !dedup.Seen("message:m_2") {
+		t.Error("the handled message should be marked as seen")
+	}
+}
+
+func TestDeduplicator(t *testing.T) {
+	d := NewDeduplicator(2)
+	if d.Seen("a") || d.Seen("b") {
+		t.Error("new keys reported as seen")
+	}
+	d.Mark("a")
+	d.Mark("b")
+	if !d.Seen("a") {
+		t.Error("key a should be seen")
+	}
+	d.Mark("c")
+	if d.Seen("b") {
+		t.Error("the oldest key should be forgotten")
+	}
+}
@@ -1,6 +1,18 @@
 package messenger
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Graph API error codes
+// https://developers.facebook.com/docs/messenger-platform/reference/send-api/error-codes
+const (
+	ErrorCodePermissionDenied           = 10
+	ErrorCodeInvalidParameter           = 100
+	ErrorSubcodeAttachmentUploadFailure = 2018047
+)
 
 type RawError struct {
 	Error *Error `json:"error"`
@@ -19,3 +31,12 @@ type Error struct {
 func (e Error) Error() string {
 	return fmt.Sprintf("[%d] %s", e.Code, e.Message)
 }
+
+// IsPermissionError reports whether err is a Graph API error caused by missing permissions
+func IsPermissionError(err error) bool {
+	graphErr, ok := errors.Cause(err).(Error)
+	if !ok {
+		return false
+	}
+	return graphErr.Code == ErrorCodePermissionDenied || (graphErr.Code >= 200 && graphErr.Code < 300)
+}
@@ -32,7 +32,7 @@ type Entry struct {
 	Message              *MessageEcho                  `json:"message,omitempty"`
 	Delivery             *Delivery                     `json:"delivery,omitempty"`
 	Postback             *Postback                     `json:"postback,omitempty"`
-	Optin                *Optin                        `json:"optin,empty"`
+	Optin                *Optin                        `json:"optin,omitempty"`
 	Read                 *Read                         `json:"read,omitempty"`
 	Referral             *Referral                     `json:"referral,omitempty"`
 	AppRoles             *AppRolesCallback             `json:"app_roles,omitempty"`
@@ -40,6 +40,9 @@ type Entry struct {
 	TakeThreadControl    *TakeThreadControlCallback    `json:"take_thread_control,omitempty"`
 	RequestThreadControl *RequestThreadControlCallback `json:"request_thread_control,omitempty"`
 	NLP                  *NLP                          `json:"nlp,omitempty"`
+	Reaction             *Reaction                     `json:"reaction,omitempty"`
+	MessageEdit          *MessageEdit                  `json:"message_edit,omitempty"`
+	AccountLinking       *AccountLinking               `json:"account_linking,omitempty"`
 }
 
 // MessageEvent encapsulates common info plus the specific type of callback
@@ -47,8 +50,9 @@ type Entry struct {
 // https://developers.facebook.com/docs/messenger-platform/webhook-reference#format
 type MessageEvent struct {
 	Event
-	Messaging []Entry `json:"messaging"`
-	Standby   []Entry `json:"standby"`
+	Messaging []Entry  `json:"messaging"`
+	Standby   []Entry  `json:"standby"`
+	Changes   []Change `json:"changes,omitempty"`
 }
 
 // AppRole is a specific type for AppRoles
@@ -96,6 +100,42 @@ type ReceivedMessage struct {
 	QuickReply  *QuickReplyPayload `json:"quick_reply,omitempty"`
 	IsEcho      bool               `json:"is_echo,omitempty"`
 	Metadata    *string            `json:"metadata,omitempty"`
+	ReplyTo     *ReplyTo           `json:"reply_to,omitempty"`
+	IsDeleted   bool               `json:"is_deleted,omitempty"`
+}
+
+// ReplyTo references the message the user replied to
+type ReplyTo struct {
+	MID string `json:"mid"`
+}
+
+// ReactionAction tells whether the reaction has been added or removed
+type ReactionAction string
+
+// ReactionAction* are the reaction actions
+const (
+	ReactionActionReact   ReactionAction = "react"
+	ReactionActionUnreact ReactionAction = "unreact"
+)
+
+// Reaction contains information specific to a message reaction callback.
+// https://developers.facebook.com/docs/messenger-platform/reference/webhook-events/message-reactions
+type Reaction struct {
+	// Reaction is the name of the reaction: smile, angry, sad, wow, love, like, dislike or other
+	Reaction string         `json:"reaction,omitempty"`
+	Emoji    string         `json:"emoji,omitempty"`
+	Action   ReactionAction `json:"action"`
+	// MID is the id of the message reacted to
+	MID string `json:"mid"`
+}
+
+// MessageEdit contains information specific to a message edit callback.
+// https://developers.facebook.com/docs/messenger-platform/reference/webhook-events/message-edits
+type MessageEdit struct {
+	// MID is the id of the edited message
+	MID     string `json:"mid"`
+	Text    string `json:"text"`
+	NumEdit int    `json:"num_edit"`
 }
 
 type MessagingReferralSource string
@@ -152,6 +192,21 @@ type Postback struct {
 // https://developers.facebook.com/docs/messenger-platform/webhook-reference/optins
 type Optin struct {
 	Ref string `json:"ref"`
+	// UserRef identifies the user who opted in with the checkbox plugin, the sender is not set then
+	UserRef string    `json:"user_ref,omitempty"`
+	Type    OptinType `json:"type,omitempty"`
+	Payload string    `json:"payload,omitempty"`
+	Title   string    `json:"title,omitempty"`
+	// OneTimeNotifToken allows sending one message after the messaging window, see OptinTypeOneTimeNotifReq
+	OneTimeNotifToken string `json:"one_time_notif_token,omitempty"`
+	// NotificationMessagesToken allows sending recurring notifications until it expires, see OptinTypeNotificationMessages
+	NotificationMessagesToken     string                        `json:"notification_messages_token,omitempty"`
+	NotificationMessagesFrequency NotificationMessagesFrequency `json:"notification_messages_frequency,omitempty"`
+	NotificationMessagesTimezone  string                        `json:"notification_messages_timezone,omitempty"`
+	NotificationMessagesStatus    NotificationMessagesStatus    `json:"notification_messages_status,omitempty"`
+	// TokenExpiryTimestamp is the expiry of the notification messages token in milliseconds
+	TokenExpiryTimestamp int64  `json:"token_expiry_timestamp,omitempty"`
+	UserTokenStatus      string `json:"user_token_status,omitempty"`
 }
 
 // Read contains data specific to message read callbacks.
@@ -0,0 +1,116 @@
+package messenger
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ChangeFieldFeed is the field of the page feed changes
+const ChangeFieldFeed = "feed"
+
+// FeedItem is the type of the changed feed object
+type FeedItem string
+
+// FeedItem* are the feed items
+// https://developers.facebook.com/docs/graph-api/webhooks/reference/page/#feed
+const (
+	FeedItemComment  FeedItem = "comment"
+	FeedItemPost     FeedItem = "post"
+	FeedItemStatus   FeedItem = "status"
+	FeedItemPhoto    FeedItem = "photo"
+	FeedItemVideo    FeedItem = "video"
+	FeedItemShare    FeedItem = "share"
+	FeedItemReaction FeedItem = "reaction"
+	FeedItemLike     FeedItem = "like"
+)
+
+// FeedVerb is the kind of the feed change
+type FeedVerb string
+
+// FeedVerb* are the feed change verbs
+const (
+	FeedVerbAdd    FeedVerb = "add"
+	FeedVerbEdited FeedVerb = "edited"
+	FeedVerbRemove FeedVerb = "remove"
+	FeedVerbHide   FeedVerb = "hide"
+	FeedVerbUnhide FeedVerb = "unhide"
+)
+
+// Change is an element of entry[].changes, sent for the page subscriptions other than messaging, e.g. feed
+// https://developers.facebook.com/docs/graph-api/webhooks/reference/page
+type Change struct {
+	Field string          `json:"field"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Feed decodes the value of a feed change, ok is false for the other fields
+func (c Change) Feed() (change *FeedChange, ok bool, err error) {
+	if c.Field != ChangeFieldFeed {
+		return nil, false, nil
+	}
+	var f FeedChange
+	if err := json.Unmarshal(c.Value, &f); err != nil {
+		return nil, true, errors.Wrapf(err, "Change.Feed/json.Unmarshal(%s)", c.Value)
+	}
+	return &f, true, nil
+}
+
+// FeedFrom is the author of a feed object
+type FeedFrom struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// FeedPost describes the post of a feed change
+type FeedPost struct {
+	ID              string `json:"id"`
+	StatusType      string `json:"status_type,omitempty"`
+	IsPublished     bool   `json:"is_published,omitempty"`
+	UpdatedTime     string `json:"updated_time,omitempty"`
+	PermalinkURL    string `json:"permalink_url,omitempty"`
+	PromotionStatus string `json:"promotion_status,omitempty"`
+}
+
+// FeedChange is the value of a feed change: a comment, post or reaction added, edited or removed on the page
+type FeedChange struct {
+	Item         FeedItem  `json:"item"`
+	Verb         FeedVerb  `json:"verb"`
+	From         FeedFrom  `json:"from"`
+	PostID       string    `json:"post_id,omitempty"`
+	CommentID    string    `json:"comment_id,omitempty"`
+	ParentID     string    `json:"parent_id,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	CreatedTime  int64     `json:"created_time,omitempty"`
+	ReactionType string    `json:"reaction_type,omitempty"`
+	Published    int       `json:"published,omitempty"`
+	IsHidden     bool      `json:"is_hidden,omitempty"`
+	Link         string    `json:"link,omitempty"`
+	Photo        string    `json:"photo,omitempty"`
+	Photos       []string  `json:"photos,omitempty"`
+	VideoID      string    `json:"video_id,omitempty"`
+	Post         *FeedPost `json:"post,omitempty"`
+}
+
+// IsNewComment reports whether the change is a comment added to the page
+func (f FeedChange) IsNewComment() bool {
+	return f.Item == FeedItemComment && f.Verb == FeedVerbAdd
+}
+
+// IsNewVisitorPost reports whether the change is a post added to the page by someone else than the page
+func (f FeedChange) IsNewVisitorPost(pageID string) bool {
+	return (f.Item == FeedItemPost || f.Item == FeedItemStatus || f.Item == FeedItemPhoto) &&
+		f.Verb == FeedVerbAdd && f.From.ID != pageID
+}
+
+// PrivateReplyRecipient returns the recipient of a private reply to the author of the comment or the visitor post,
+// ok is false if the change can't be replied privately.
+func (f FeedChange) PrivateReplyRecipient() (Recipient, bool) {
+	switch {
+	case f.Item == FeedItemComment && f.CommentID != "":
+		return Recipient{CommentID: f.CommentID}, true
+	case f.Item != FeedItemComment && f.Item != FeedItemReaction && f.Item != FeedItemLike && f.PostID != "":
+		return Recipient{PostID: f.PostID}, true
+	}
+	return Recipient{}, false
+}
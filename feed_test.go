@@ -0,0 +1,63 @@
+package messenger
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const feedPayload = `{
+	"object": "page",
+	"entry": [{
+		"id": "100",
+		"time": 1520383571,
+		"changes": [
+			{"field": "feed", "value": {
+				"item": "comment", "verb": "add", "post_id": "100_200", "comment_id": "200_300", "parent_id": "100_200",
+				"from": {"id": "400", "name": "Visitor"}, "message": "Where is my order?", "created_time": 1520383571,
+				"post": {"id": "100_200", "status_type": "added_photos", "is_published": true}
+			}},
+			{"field": "feed", "value": {"item": "reaction", "verb": "add", "post_id": "100_200", "reaction_type": "love", "from": {"id": "400"}}},
+			{"field": "feed", "value": {"item": "status", "verb": "add", "post_id": "100_500", "from": {"id": "400"}, "message": "Hello page"}},
+			{"field": "mention", "value": {"post_id": "100_600"}}
+		]
+	}]
+}`
+
+func TestFeedChanges(t *testing.T) {
+	var event UpstreamEvent
+	if err := json.Unmarshal([]byte(feedPayload), &event); err != nil {
+		t.Fatal(err)
+	}
+	changes := event.Entries[0].Changes
+	if len(changes) != 4 {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+
+	comment, ok, err := changes[0].Feed()
+	if err != nil || !ok {
+		t.Fatalf("feed change expected: %v", err)
+	}
+	if !comment.IsNewComment() || comment.From.Name != "Visitor" || comment.Post == nil || comment.Post.StatusType != "added_photos" {
+		t.Errorf("unexpected comment: %+v", comment)
+	}
+	if r, ok := comment.PrivateReplyRecipient(); !ok || r != (Recipient{CommentID: "200_300"}) {
+		t.Errorf("unexpected recipient: %+v", r)
+	}
+
+	reaction, _, _ := changes[1].Feed()
+	if _, ok := reaction.PrivateReplyRecipient(); ok || reaction.ReactionType != "love" {
+		t.Errorf("reactions can't be replied privately: %+v", reaction)
+	}
+
+	post, _, _ := changes[2].Feed()
+	if !post.IsNewVisitorPost("100") || post.IsNewVisitorPost("400") {
+		t.Errorf("unexpected visitor post detection: %+v", post)
+	}
+	if r, ok := post.PrivateReplyRecipient(); !ok || r != (Recipient{PostID: "100_500"}) {
+		t.Errorf("unexpected recipient: %+v", r)
+	}
+
+	if _, ok, _ := changes[3].Feed(); ok {
+		t.Error("mention is not a feed change")
+	}
+}
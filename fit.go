@@ -0,0 +1,103 @@
+package messenger
+
+import "fmt"
+
+// Ellipsis is appended to the texts shortened to fit the Messenger limits
+const Ellipsis = "…"
+
+// FitAction describes how a value was changed to fit the Messenger limits
+type FitAction string
+
+// FitAction* are the possible changes made by the fit functions
+const (
+	FitActionTruncated FitAction = "truncated"
+	FitActionDropped   FitAction = "dropped"
+	FitActionSplit     FitAction = "split"
+)
+
+// FitChange is a single modification made while fitting content to the Messenger limits
+type FitChange struct {
+	// Path locates the changed value in the original content, e.g. elements[2].buttons[0].title
+	Path   string    `json:"path"`
+	Action FitAction `json:"action"`
+	// Detail holds the original text of a truncated value, the title of a dropped item
+	// or a short description of a split.
+	Detail string `json:"detail,omitempty"`
+}
+
+// String returns a human readable form of the change
+func (c FitChange) String() string {
+	return fmt.Sprintf("%s %s: %s", c.Path, c.Action, c.Detail)
+}
+
+// FitReport lists the changes made while fitting content to the Messenger limits
+type FitReport []FitChange
+
+// Add appends a change to the report
+func (r *FitReport) Add(path string, action FitAction, detail string) {
+	*r = append(*r, FitChange{
+		Path:   path,
+		Action: action,
+		Detail: detail,
+	})
+}
+
+// Merge appends the changes of the other report to r
+func (r *FitReport) Merge(other FitReport) {
+	*r = append(*r, other...)
+}
+
+// Changed reports whether the content has been modified
+func (r FitReport) Changed() bool {
+	return len(r) > 0
+}
+
+// Truncate shortens s to at most limit characters, replacing the end of the text with an Ellipsis.
+// The text is cut at character boundaries, so accents and emoji sequences are never broken.
+// The second return value reports whether s has been shortened.
+func Truncate(s string, limit int) (string, bool) {
+	if !ExceedsLimit(s, limit) {
+		return s, false
+	}
+	if limit <= 0 {
+		return "", true
+	}
+
+	end := 0
+	for i := 0; i < limit-1; i++ {
+		end += characterLength(s[end:])
+	}
+	for end > 0 && (s[end-1] == ' ' || s[end-1] == '\n' || s[end-1] == '\t') {
+		end--
+	}
+	return s[:end] + Ellipsis, true
+}
+
+// FitText truncates the text to the limit and records the change in the report
+func FitText(s string, limit int, path string, report *FitReport) string {
+	fitted, truncated := Truncate(s, limit)
+	if truncated {
+		report.Add(path, FitActionTruncated, s)
+	}
+	return fitted
+}
+
+// FitQuickReplies returns a copy of the quick replies complying with the Send API limits:
+// titles are truncated and the quick replies above the limit are dropped.
+func FitQuickReplies(quickReplies []QuickReply) ([]QuickReply, FitReport) {
+	var report FitReport
+	if quickReplies == nil {
+		return nil, report
+	}
+
+	fitted := make([]QuickReply, 0, len(quickReplies))
+	for i, qr := range quickReplies {
+		if i >= QuickRepliesLimit {
+			report.Add(fmt.Sprintf("quick_replies[%d]", i), FitActionDropped, qr.Title)
+			continue
+		}
+		qr.Title = FitText(qr.Title, QuickReplyTitleLengthLimit, fmt.Sprintf("quick_replies[%d].title", i), &report)
+		fitted = append(fitted, qr)
+	}
+	return fitted, report
+}
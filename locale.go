@@ -0,0 +1,122 @@
+package messenger
+
+import "strings"
+
+// SupportedLocales lists the locales supported by the Messenger Profile API
+// https://developers.facebook.com/docs/messenger-platform/messenger-profile/supported-locales
+var SupportedLocales = []string{
+	"af_ZA", "ar_AR", "as_IN", "az_AZ", "be_BY", "bg_BG", "bn_IN", "br_FR", "bs_BA", "ca_ES",
+	"cb_IQ", "co_FR", "cs_CZ", "cx_PH", "cy_GB", "da_DK", "de_DE", "el_GR", "en_GB", "en_UD",
+	"en_US", "es_ES", "es_LA", "et_EE", "eu_ES", "fa_IR", "ff_NG", "fi_FI", "fo_FO", "fr_CA",
+	"fr_FR", "fy_NL", "ga_IE", "gl_ES", "gn_PY", "gu_IN", "ha_NG", "he_IL", "hi_IN", "hr_HR",
+	"hu_HU", "hy_AM", "id_ID", "is_IS", "it_IT", "ja_JP", "ja_KS", "jv_ID", "ka_GE", "kk_KZ",
+	"km_KH", "kn_IN", "ko_KR", "ku_TR", "lt_LT", "lv_LV", "mg_MG", "mk_MK", "ml_IN", "mn_MN",
+	"mr_IN", "ms_MY", "mt_MT", "my_MM", "nb_NO", "ne_NP", "nl_BE", "nl_NL", "nn_NO", "or_IN",
+	"pa_IN", "pl_PL", "ps_AF", "pt_BR", "pt_PT", "qz_MM", "ro_RO", "ru_RU", "rw_RW", "sc_IT",
+	"si_LK", "sk_SK", "sl_SI", "so_SO", "sq_AL", "sr_RS", "sv_SE", "sw_KE", "sz_PL", "ta_IN",
+	"te_IN", "tg_TJ", "th_TH", "tl_PH", "tr_TR", "tz_MA", "uk_UA", "ur_PK", "uz_UZ", "vi_VN",
+	"zh_CN", "zh_HK", "zh_TW",
+}
+
+var supportedLocales = func() map[string]bool {
+	m := make(map[string]bool, len(SupportedLocales))
+	for _, l := range SupportedLocales {
+		m[l] = true
+	}
+	return m
+}()
+
+// IsSupportedLocale reports whether the locale can be used in the settings, DefaultLocale included
+func IsSupportedLocale(locale string) bool {
+	return locale == DefaultLocale || supportedLocales[locale]
+}
+
+// ResolveLocale picks the best locale of the available ones for the user's locale (e.g. Profile.Locale):
+// the exact match, then the first locale of the same language, then DefaultLocale.
+// The second return value is false if none of them is available.
+func ResolveLocale(available []string, locale string) (string, bool) {
+	locale = normalizeLocale(locale)
+	language := localeLanguage(locale)
+
+	sameLanguage, hasDefault := -1, false
+	for i, l := range available {
+		n := normalizeLocale(l)
+		switch {
+		case n == locale && locale != "":
+			return l, true
+		case sameLanguage < 0 && language != "" && localeLanguage(n) == language:
+			sameLanguage = i
+		case l == DefaultLocale:
+			hasDefault = true
+		}
+	}
+
+	if sameLanguage >= 0 {
+		return available[sameLanguage], true
+	}
+	if hasDefault {
+		return DefaultLocale, true
+	}
+	return "", false
+}
+
+// Greeting returns the greeting for the user's locale, see ResolveLocale for the fallback rules
+func (s Settings) Greeting(locale string) (Greeting, bool) {
+	locales := make([]string, len(s.Greetings))
+	for i, g := range s.Greetings {
+		locales[i] = g.Locale
+	}
+	l, ok := ResolveLocale(locales, locale)
+	for _, g := range s.Greetings {
+		if ok && g.Locale == l {
+			return g, true
+		}
+	}
+	return Greeting{}, false
+}
+
+// Menu returns the persistent menu for the user's locale, see ResolveLocale for the fallback rules
+func (s Settings) Menu(locale string) (PersistentMenu, bool) {
+	locales := make([]string, len(s.PersistentMenu))
+	for i, m := range s.PersistentMenu {
+		locales[i] = m.Locale
+	}
+	l, ok := ResolveLocale(locales, locale)
+	for _, m := range s.PersistentMenu {
+		if ok && m.Locale == l {
+			return m, true
+		}
+	}
+	return PersistentMenu{}, false
+}
+
+// IceBreakersFor returns the ice breakers for the user's locale, see ResolveLocale for the fallback rules
+func (s Settings) IceBreakersFor(locale string) (IceBreakers, bool) {
+	locales := make([]string, len(s.IceBreakers))
+	for i, ib := range s.IceBreakers {
+		locales[i] = ib.Locale
+	}
+	l, ok := ResolveLocale(locales, locale)
+	for _, ib := range s.IceBreakers {
+		if ok && ib.Locale == l {
+			return ib, true
+		}
+	}
+	return IceBreakers{}, false
+}
+
+// normalizeLocale converts locales like fr-ca to the fr_CA form
+func normalizeLocale(locale string) string {
+	parts := strings.SplitN(strings.Replace(locale, "-", "_", -1), "_", 2)
+	if len(parts) == 1 {
+		return strings.ToLower(parts[0])
+	}
+	return strings.ToLower(parts[0]) + "_" + strings.ToUpper(parts[1])
+}
+
+func localeLanguage(locale string) string {
+	if locale == DefaultLocale {
+		return ""
+	}
+	return strings.SplitN(locale, "_", 2)[0]
+}
@@ -0,0 +1,75 @@
+package messenger
+
+import "testing"
+
+func TestIsSupportedLocale(t *testing.T) {
+	for _, l := range []string{DefaultLocale, "hu_HU", "fr_CA", "zh_TW"} {
+		if !IsSupportedLocale(l) {
+			t.Errorf("%s is not supported", l)
+		}
+	}
+	for _, l := range []string{"", "hu", "hu-HU", "xx_XX"} {
+		if IsSupportedLocale(l) {
+			t.Errorf("%s is supported", l)
+		}
+	}
+
+	s := Settings{}
+	s.AddGreeting(DefaultLocale, "Hello")
+	s.AddGreeting("hu", "Szia")
+	if !hasValidationError(s.Validate(), "greeting[1].locale") {
+		t.Error("unsupported locale is accepted")
+	}
+}
+
+func TestResolveLocale(t *testing.T) {
+	available := []string{"en_US", "fr_FR", DefaultLocale, "pt_BR"}
+	tests := []struct {
+		locale string
+		want   string
+		ok     bool
+	}{
+		{"fr_FR", "fr_FR", true},
+		{"fr_CA", "fr_FR", true},
+		{"fr-ca", "fr_FR", true},
+		{"en_GB", "en_US", true},
+		{"pt_PT", "pt_BR", true},
+		{"hu_HU", DefaultLocale, true},
+		{"", DefaultLocale, true},
+	}
+	for _, test := range tests {
+		got, ok := ResolveLocale(available, test.locale)
+		if got != test.want || ok != test.ok {
+			t.Errorf("ResolveLocale(%s) = %s, %v, want %s, %v", test.locale, got, ok, test.want, test.ok)
+		}
+	}
+
+	if _, ok := ResolveLocale([]string{"en_US"}, "hu_HU"); ok {
+		t.Error("locale resolved without default")
+	}
+}
+
+func TestSettingsLocalized(t *testing.T) {
+	s := Settings{}
+	s.AddGreeting(DefaultLocale, "Hello")
+	s.AddGreeting("fr_FR", "Bonjour")
+	s.AddPersistentMenu(DefaultLocale, false)
+	s.AddPersistentMenu("fr_FR", true)
+	s.AddIceBreaker("fr_FR", "Où êtes-vous?", "where")
+
+	if g, ok := s.Greeting("fr_CA"); !ok || g.Text != "Bonjour" {
+		t.Errorf("unexpected greeting: %v", g)
+	}
+	if g, ok := s.Greeting("hu_HU"); !ok || g.Text != "Hello" {
+		t.Errorf("unexpected greeting: %v", g)
+	}
+	if m, ok := s.Menu("fr_CA"); !ok || !m.InputDisabled {
+		t.Errorf("unexpected menu: %v", m)
+	}
+	if _, ok := s.IceBreakersFor("hu_HU"); ok {
+		t.Error("ice breakers resolved without default")
+	}
+	if ib, ok := s.IceBreakersFor("fr_CA"); !ok || ib.Locale != "fr_FR" {
+		t.Errorf("unexpected ice breakers: %v", ib)
+	}
+}
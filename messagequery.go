@@ -1,5 +1,21 @@
 package messenger
 
+import "errors"
+
+// Message limits
+const (
+	SendMessageTextLengthLimit = 2000
+	QuickRepliesLimit          = 13
+	QuickReplyTitleLengthLimit = 20
+)
+
+// Message validation errors
+var (
+	ErrTextLengthExceeded            = errors.New("Message text exceeds the 2000 character limit")
+	ErrQuickRepliesLimitExceeded     = errors.New("Limit of 13 quick replies exceeded")
+	ErrQuickReplyTitleLengthExceeded = errors.New("Quick reply title exceeds the 20 character limit")
+)
+
 // ContentType is a specific string type
 type ContentType string
 
@@ -32,6 +48,24 @@ type SendMessage struct {
 	Metadata     string       `json:"metadata,omitempty"`
 }
 
+// Validate checks the message against the text and quick reply limits of the Send API
+func (m SendMessage) Validate() error {
+	if ExceedsLimit(m.Text, SendMessageTextLengthLimit) {
+		return ErrTextLengthExceeded
+	}
+
+	if len(m.QuickReplies) > QuickRepliesLimit {
+		return ErrQuickRepliesLimitExceeded
+	}
+
+	for _, qr := range m.QuickReplies {
+		if ExceedsLimit(qr.Title, QuickReplyTitleLengthLimit) {
+			return ErrQuickReplyTitleLengthExceeded
+		}
+	}
+	return nil
+}
+
 // QuickReply ...
 type QuickReply struct {
 	ContentType ContentType `json:"content_type"`
@@ -41,10 +75,21 @@ type QuickReply struct {
 }
 
 // Recipient describes the person who will receive the message
-// Either ID or PhoneNumber has to be set
+// Either ID or PhoneNumber has to be set, or CommentID or PostID for a private reply,
+// or one of UserRef and the opt-in tokens for a message following an opt-in, see Entry.OptinRecipient
 type Recipient struct {
 	ID          string `json:"id,omitempty"`
 	PhoneNumber string `json:"phone_number,omitempty"`
+	// CommentID sends a private reply to the author of the page comment
+	CommentID string `json:"comment_id,omitempty"`
+	// PostID sends a private reply to the author of the visitor post
+	PostID string `json:"post_id,omitempty"`
+	// UserRef sends the message to the user who opted in with the checkbox plugin
+	UserRef string `json:"user_ref,omitempty"`
+	// OneTimeNotifToken sends the one time notification the user asked for
+	OneTimeNotifToken string `json:"one_time_notif_token,omitempty"`
+	// NotificationMessagesToken sends a recurring notification the user subscribed to
+	NotificationMessagesToken string `json:"notification_messages_token,omitempty"`
 }
 
 // NotificationType describes the behavior phone will execute after receiving the message
@@ -53,6 +98,18 @@ type NotificationType string
 // MessagingType ...
 type MessagingType string
 
+// MessageTag allows sending messages outside of the standard messaging window
+// https://developers.facebook.com/docs/messenger-platform/send-messages/message-tags
+type MessageTag string
+
+// MessageTag* are the supported message tags
+const (
+	MessageTagConfirmedEventUpdate MessageTag = "CONFIRMED_EVENT_UPDATE"
+	MessageTagPostPurchaseUpdate   MessageTag = "POST_PURCHASE_UPDATE"
+	MessageTagAccountUpdate        MessageTag = "ACCOUNT_UPDATE"
+	MessageTagHumanAgent           MessageTag = "HUMAN_AGENT"
+)
+
 // MessageQuery ...
 type MessageQuery struct {
 	Recipient        Recipient        `json:"recipient" form:"recipient"`
@@ -60,5 +117,6 @@ type MessageQuery struct {
 	NotificationType NotificationType `json:"notification_type,omitempty" form:"notification_type,omitempty"`
 	Action           SenderAction     `json:"sender_action,omitempty" form:"sender_action,omitempty"`
 	MessagingType    MessagingType    `json:"messaging_type,omitempty" form:"messaging_type,omitempty"`
+	Tag              MessageTag       `json:"tag,omitempty" form:"tag,omitempty"`
 	PersonaID        string           `json:"persona_id,omitempty" form:"persona_id,omitempty"`
 }
@@ -0,0 +1,86 @@
+package messenger
+
+import (
+	"errors"
+	"time"
+)
+
+// OptinType is the type of an opt-in callback
+type OptinType string
+
+// OptinType* are the opt-in types, the opt-ins of the send to messenger and checkbox plugins have no type
+const (
+	OptinTypeOneTimeNotifReq      OptinType = "one_time_notif_req"
+	OptinTypeNotificationMessages OptinType = "notification_messages"
+)
+
+// NotificationMessagesFrequency is how often recurring notifications can be sent
+type NotificationMessagesFrequency string
+
+// NotificationMessagesFrequency* are the frequencies of the recurring notifications
+const (
+	NotificationMessagesFrequencyDaily   NotificationMessagesFrequency = "DAILY"
+	NotificationMessagesFrequencyWeekly  NotificationMessagesFrequency = "WEEKLY"
+	NotificationMessagesFrequencyMonthly NotificationMessagesFrequency = "MONTHLY"
+)
+
+// NotificationMessagesStatus tells whether the user stopped or resumed the recurring notifications
+type NotificationMessagesStatus string
+
+// NotificationMessagesStatus* are the statuses of the recurring notifications
+const (
+	NotificationMessagesStatusStop   NotificationMessagesStatus = "STOP_NOTIFICATIONS"
+	NotificationMessagesStatusResume NotificationMessagesStatus = "RESUME_NOTIFICATIONS"
+)
+
+// Opt-in errors
+var (
+	ErrOptinTokenExpired         = errors.New("opt-in notification messages token expired")
+	ErrOptinNotificationsStopped = errors.New("opt-in notification messages stopped by the user")
+	ErrOptinNoRecipient          = errors.New("opt-in has neither token, user_ref nor sender")
+)
+
+// Expiry returns the expiry of the notification messages token, zero if it has no expiry
+func (o Optin) Expiry() time.Time {
+	if o.TokenExpiryTimestamp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, o.TokenExpiryTimestamp*int64(time.Millisecond))
+}
+
+// Expired reports whether the notification messages token has expired at t
+func (o Optin) Expired(t time.Time) bool {
+	expiry := o.Expiry()
+	return !expiry.IsZero() && !t.Before(expiry)
+}
+
+// Recipient returns the recipient of a follow-up message to the user who opted in:
+// the one time notification token, the notification messages token, the checkbox plugin's user_ref
+// or senderID, in this order. Expired and stopped notification messages tokens are rejected.
+func (o Optin) Recipient(senderID string) (Recipient, error) {
+	switch {
+	case o.OneTimeNotifToken != "":
+		return Recipient{OneTimeNotifToken: o.OneTimeNotifToken}, nil
+	case o.NotificationMessagesToken != "":
+		if o.NotificationMessagesStatus == NotificationMessagesStatusStop {
+			return Recipient{}, ErrOptinNotificationsStopped
+		}
+		if o.Expired(time.Now()) {
+			return Recipient{}, ErrOptinTokenExpired
+		}
+		return Recipient{NotificationMessagesToken: o.NotificationMessagesToken}, nil
+	case o.UserRef != "":
+		return Recipient{UserRef: o.UserRef}, nil
+	case senderID != "":
+		return Recipient{ID: senderID}, nil
+	}
+	return Recipient{}, ErrOptinNoRecipient
+}
+
+// OptinRecipient returns the recipient of a follow-up message to the user who opted in, see Optin.Recipient
+func (e Entry) OptinRecipient() (Recipient, error) {
+	if e.Optin == nil {
+		return Recipient{}, ErrOptinNoRecipient
+	}
+	return e.Optin.Recipient(e.Sender.ID)
+}
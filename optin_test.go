@@ -0,0 +1,85 @@
+package messenger
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestOptinRecipient(t *testing.T) {
+	future := time.Now().Add(time.Hour).UnixNano() / int64(time.Millisecond)
+	past := time.Now().Add(-time.Hour).UnixNano() / int64(time.Millisecond)
+
+	tests := []struct {
+		optin     string
+		sender    string
+		recipient Recipient
+		err       error
+	}{
+		{`{"ref":"checkout","user_ref":"ref_1"}`, "", Recipient{UserRef: "ref_1"}, nil},
+		{`{"type":"one_time_notif_req","payload":"PRICE_DROP","one_time_notif_token":"otn_1"}`, "400", Recipient{OneTimeNotifToken: "otn_1"}, nil},
+		{`{"type":"notification_messages","notification_messages_token":"nm_1","notification_messages_frequency":"WEEKLY","token_expiry_timestamp":` + strconv.FormatInt(future, 10) + `}`, "400", Recipient{NotificationMessagesToken: "nm_1"}, nil},
+		{`{"type":"notification_messages","notification_messages_token":"nm_1","token_expiry_timestamp":` + strconv.FormatInt(past, 10) + `}`, "400", Recipient{}, ErrOptinTokenExpired},
+		{`{"type":"notification_messages","notification_messages_token":"nm_1","notification_messages_status":"STOP_NOTIFICATIONS"}`, "400", Recipient{}, ErrOptinNotificationsStopped},
+		{`{"ref":"landing"}`, "400", Recipient{ID: "400"}, nil},
+		{`{"ref":"landing"}`, "", Recipient{}, ErrOptinNoRecipient},
+	}
+	for _, test := range tests {
+		var e Entry
+		if err := json.Unmarshal([]byte(`{"sender":{"id":"`+test.sender+`"},"optin":`+test.optin+`}`), &e); err != nil {
+			t.Fatal(err)
+		}
+		r, err := e.OptinRecipient()
+		if r != test.recipient || err != test.err {
+			t.Errorf("OptinRecipient(%s) = %+v, %v, expected %+v, %v", test.optin, r, err, test.recipient, test.err)
+		}
+	}
+}
+
+func TestOptinFields(t *testing.T) {
+	var o Optin
+	payload := `{"type":"notification_messages","payload":"DEALS","title":"Weekly deals","notification_messages_token":"nm_1",
+		"notification_messages_frequency":"WEEKLY","notification_messages_timezone":"Europe/Budapest",
+		"token_expiry_timestamp":1700000000000,"user_token_status":"NOT_REFRESHED"}`
+	if err := json.Unmarshal([]byte(payload), &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.Type != OptinTypeNotificationMessages || o.Payload != "DEALS" || o.NotificationMessagesFrequency != NotificationMessagesFrequencyWeekly {
+		t.Errorf("unexpected optin: %+v", o)
+	}
+	if !o.Expiry().Equal(time.Unix(1700000000, 0)) || !o.Expired(time.Unix(1700000000, 0)) || o.Expired(time.Unix(1699999999, 0)) {
+		t.Errorf("unexpected expiry: %v", o.Expiry())
+	}
+	if (Optin{}).Expired(time.Now()) {
+		t.Error("optin without expiry should not expire")
+	}
+}
+
+func TestOptinDedupKey(t *testing.T) {
+	var e Entry
+	payload := `{"sender":{"id":"400"},"timestamp":5,"optin":{"type":"notification_messages","notification_messages_token":"nm_1","token_expiry_timestamp":1}}`
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		t.Fatal(err)
+	}
+	if key := e.DedupKey(); key != "optin:400:::nm_1:5" {
+		t.Errorf("unexpected optin key of an expired token: %s", key)
+	}
+
+	e.Optin.NotificationMessagesToken = ""
+	e.Optin.UserRef = "ref_1"
+	if key := e.DedupKey(); key != "optin:400:ref_1:::5" {
+		t.Errorf("unexpected optin key: %s", key)
+	}
+}
+
+func TestOptinMessage(t *testing.T) {
+	q, err := NewMessageBuilder().ToRecipient(Recipient{OneTimeNotifToken: "otn_1"}).Text("Price dropped!").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(q.Recipient)
+	if string(b) != `{"one_time_notif_token":"otn_1"}` {
+		t.Errorf("unexpected recipient: %s", b)
+	}
+}
@@ -0,0 +1,150 @@
+package messenger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Paging is the paging of Graph API lists
+// https://developers.facebook.com/docs/graph-api/results
+type Paging struct {
+	Cursors  Cursors `json:"cursors"`
+	Next     string  `json:"next,omitempty"`
+	Previous string  `json:"previous,omitempty"`
+}
+
+// Cursors are the cursors of a list page
+type Cursors struct {
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// listPage is a page of a Graph API list
+type listPage struct {
+	Data   []json.RawMessage `json:"data"`
+	Paging *Paging           `json:"paging,omitempty"`
+}
+
+// ListIterator iterates over the items of a Graph API list endpoint like me/personas,
+// requesting the next page with the after cursor only when the items of the current one are consumed.
+//
+//	it := c.List(ctx, accessToken, "me/personas", 25)
+//	for it.Next() {
+//		var p Persona
+//		if err := it.Decode(&p); err != nil {
+//			return err
+//		}
+//	}
+//	return it.Err()
+type ListIterator struct {
+	ctx      context.Context
+	client   *http.Client
+	url      string
+	after    string
+	items    []json.RawMessage
+	current  json.RawMessage
+	lastPage bool
+	err      error
+}
+
+// List returns an iterator over the items of the list at the path, which may contain query parameters like fields.
+// pageSize sets the limit parameter of the requests, Graph API's default is used if it's 0.
+func (c *controller) List(ctx context.Context, accessToken, path string, pageSize int) *ListIterator {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	uri := fmt.Sprintf("%s/%s/%s%saccess_token=%s", GraphAPI, c.graphAPIVersion, path, separator, url.QueryEscape(accessToken))
+	if pageSize > 0 {
+		uri += "&limit=" + strconv.Itoa(pageSize)
+	}
+
+	return &ListIterator{
+		ctx:    ctx,
+		client: c.httpClient,
+		url:    uri,
+	}
+}
+
+// Next advances to the next item, fetching the next page if needed.
+// It returns false at the end of the list or on error, see Err.
+func (it *ListIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for len(it.items) == 0 {
+		if it.lastPage {
+			it.current = nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+	}
+
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Decode decodes the current item into v
+func (it *ListIterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return errors.New("ListIterator.Decode called without a current item")
+	}
+	return errors.Wrapf(json.Unmarshal(it.current, v), "ListIterator.Decode(%s)", it.current)
+}
+
+// Err returns the error stopping the iteration, nil at the end of the list
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// Cursor returns the after cursor of the last fetched page, it can be used to continue the iteration later
+func (it *ListIterator) Cursor() string {
+	return it.after
+}
+
+func (it *ListIterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+
+	uri := it.url
+	if it.after != "" {
+		uri += "&after=" + url.QueryEscape(it.after)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return errors.Wrapf(err, "ListIterator/http.NewRequest(%v, %v)", http.MethodGet, uri)
+	}
+
+	resp, err := it.client.Do(req.WithContext(it.ctx))
+	if err != nil {
+		return errors.Wrap(err, "ListIterator/c.httpClient.Do")
+	}
+	defer resp.Body.Close()
+
+	var page listPage
+	if err := decodeResponse(resp, &page); err != nil {
+		return errors.Wrap(err, "ListIterator")
+	}
+
+	it.items = page.Data
+	if len(page.Data) == 0 || page.Paging == nil || page.Paging.Next == "" || page.Paging.Cursors.After == "" {
+		it.lastPage = true
+	}
+	if page.Paging != nil && page.Paging.Cursors.After != "" {
+		it.after = page.Paging.Cursors.After
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package messenger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestListIterator(t *testing.T) {
+	var requests int
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		if r.URL.Path != "/"+GraphAPIVersion+"/me/custom_labels" || q.Get("fields") != "page_label_name" || q.Get("limit") != "2" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		switch q.Get("after") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"id":"1"},{"id":"2"}],"paging":{"cursors":{"before":"b","after":"c1"},"next":"https://graph.facebook.com/next"}}`)
+		case "c1":
+			fmt.Fprint(w, `{"data":[{"id":"3"}],"paging":{"cursors":{"before":"c1","after":"c2"}}}`)
+		default:
+			t.Errorf("unexpected cursor: %s", q.Get("after"))
+		}
+	})
+
+	it := c.List(context.Background(), "token", "me/custom_labels?fields=page_label_name", 2)
+	if requests != 0 {
+		t.Error("the first page should be fetched only by Next")
+	}
+
+	var ids []string
+	for it.Next() {
+		var item struct {
+			ID string `json:"id"`
+		}
+		if err := it.Decode(&item); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, item.ID)
+		if len(ids) == 2 && requests != 1 {
+			t.Errorf("the second page should be fetched lazily, requests: %d", requests)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" || requests != 2 {
+		t.Errorf("unexpected items %v after %d requests", ids, requests)
+	}
+	if it.Cursor() != "c2" {
+		t.Errorf("unexpected cursor: %s", it.Cursor())
+	}
+	if it.Next() {
+		t.Error("Next should return false after the end of the list")
+	}
+}
+
+func TestListIteratorErrors(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error":{"message":"Permission denied","code":10}}`)
+	})
+
+	it := c.List(context.Background(), "token", "me/conversations", 0)
+	if it.Next() {
+		t.Error("Next should return false on error")
+	}
+	if !IsPermissionError(it.Err()) {
+		t.Errorf("permission error expected, got %v", it.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = c.List(ctx, "token", "me/conversations", 0)
+	if it.Next() || it.Err() != context.Canceled {
+		t.Errorf("context error expected, got %v", it.Err())
+	}
+}
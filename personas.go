@@ -1,15 +1,43 @@
 package messenger
 
+import (
+	"errors"
+	"net/url"
+)
+
 const (
 	PersonasPath string = "personas"
 )
 
+// Persona validation errors
+var (
+	ErrPersonaNameRequired       = errors.New("persona name is required")
+	ErrPersonaPictureRequired    = errors.New("persona profile picture url is required")
+	ErrPersonaPictureURLNotValid = errors.New("persona profile picture url has to be an absolute http(s) url")
+)
+
 // Persona represents the object of persona.
 type Persona struct {
+	ID                string `json:"id,omitempty"`
 	Name              string `json:"name"`
 	ProfilePictureURL string `json:"profile_picture_url"`
 }
 
+// Validate checks the name and the profile picture url of the persona
+func (p Persona) Validate() error {
+	if p.Name == "" {
+		return ErrPersonaNameRequired
+	}
+	if p.ProfilePictureURL == "" {
+		return ErrPersonaPictureRequired
+	}
+	u, err := url.Parse(p.ProfilePictureURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrPersonaPictureURLNotValid
+	}
+	return nil
+}
+
 // PersonaResponse represents the response for create.
 type PersonaResponse struct {
 	ID string `json:"id"`
@@ -17,7 +45,8 @@ type PersonaResponse struct {
 
 // ListOfPersona represents the response of list of persona.
 type ListOfPersonaResponse struct {
-	Data []Persona `json:"data"`
+	Data   []Persona `json:"data"`
+	Paging *Paging   `json:"paging,omitempty"`
 }
 
 // DeletePersonaResponse represents the response for delete.
@@ -0,0 +1,55 @@
+package messenger
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrDuplicatePersonaName is returned if the desired personas contain the same name twice
+var ErrDuplicatePersonaName = errors.New("duplicate persona name")
+
+// ReconcilePersonas makes the page's personas match the desired ones and returns the persona ids by name,
+// which can be used to set MessageQuery.PersonaID.
+// Personas are matched by name: missing ones are created, the ones not desired and the duplicates are deleted.
+// The profile picture is used only on creation, since Graph API returns a different url for the uploaded picture;
+// rename the persona to replace its picture.
+func ReconcilePersonas(c Controller, accessToken string, desired []Persona) (map[string]string, error) {
+	wanted := make(map[string]Persona, len(desired))
+	for _, p := range desired {
+		if err := p.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "ReconcilePersonas(%s)", p.Name)
+		}
+		if _, ok := wanted[p.Name]; ok {
+			return nil, errors.Wrapf(ErrDuplicatePersonaName, "ReconcilePersonas(%s)", p.Name)
+		}
+		wanted[p.Name] = p
+	}
+
+	existing, err := c.Personas(accessToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "ReconcilePersonas")
+	}
+
+	ids := make(map[string]string, len(desired))
+	for _, p := range existing {
+		if _, ok := wanted[p.Name]; ok && ids[p.Name] == "" {
+			ids[p.Name] = p.ID
+			continue
+		}
+		if err := c.DeletePersona(accessToken, p.ID); err != nil {
+			return ids, errors.Wrapf(err, "ReconcilePersonas/DeletePersona(%s)", p.Name)
+		}
+	}
+
+	for _, p := range desired {
+		if ids[p.Name] != "" {
+			continue
+		}
+		created, err := c.AddPersona(accessToken, p)
+		if err != nil {
+			return ids, errors.Wrapf(err, "ReconcilePersonas/AddPersona(%s)", p.Name)
+		}
+		ids[p.Name] = created.ID
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,91 @@
+package messenger
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// personaController is a Controller keeping the personas in memory
+type personaController struct {
+	Controller
+	personas []Persona
+	created  []string
+	deleted  []string
+}
+
+func (c *personaController) Personas(accessToken string) ([]Persona, error) {
+	return append([]Persona(nil), c.personas...), nil
+}
+
+func (c *personaController) AddPersona(accessToken string, p Persona) (*Persona, error) {
+	p.ID = "new" + strconv.Itoa(len(c.created))
+	// Graph API re-hosts the picture and returns its CDN url
+	p.ProfilePictureURL = "https://cdn.example.com/" + p.ID + ".png"
+	c.created = append(c.created, p.Name)
+	c.personas = append(c.personas, p)
+	return &p, nil
+}
+
+func (c *personaController) DeletePersona(accessToken, personaID string) error {
+	c.deleted = append(c.deleted, personaID)
+	for i, p := range c.personas {
+		if p.ID == personaID {
+			c.personas = append(c.personas[:i], c.personas[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestReconcilePersonas(t *testing.T) {
+	c := &personaController{personas: []Persona{
+		{ID: "1", Name: "Anna", ProfilePictureURL: "https://cdn.example.com/1.png"},
+		{ID: "2", Name: "Old agent"},
+		{ID: "3", Name: "Anna"},
+	}}
+	desired := []Persona{
+		{Name: "Anna", ProfilePictureURL: "https://example.com/anna.png"},
+		{Name: "Bela", ProfilePictureURL: "https://example.com/bela.png"},
+	}
+
+	ids, err := ReconcilePersonas(c, "token", desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids["Anna"] != "1" || ids["Bela"] != "new0" {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+	if len(c.deleted) != 2 || c.deleted[0] != "2" || c.deleted[1] != "3" {
+		t.Errorf("stale and duplicate personas should be deleted: %v", c.deleted)
+	}
+
+	c.created, c.deleted = nil, nil
+	ids, err = ReconcilePersonas(c, "token", desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.created) != 0 || len(c.deleted) != 0 || ids["Bela"] != "new0" {
+		t.Errorf("second run should change nothing: created %v, deleted %v, ids %v", c.created, c.deleted, ids)
+	}
+}
+
+func TestReconcilePersonasInvalid(t *testing.T) {
+	c := &personaController{}
+	_, err := ReconcilePersonas(c, "token", []Persona{
+		{Name: "Anna", ProfilePictureURL: "https://example.com/a.png"},
+		{Name: "Anna", ProfilePictureURL: "https://example.com/b.png"},
+	})
+	if errors.Cause(err) != ErrDuplicatePersonaName {
+		t.Errorf("duplicate name error expected, got %v", err)
+	}
+
+	_, err = ReconcilePersonas(c, "token", []Persona{{Name: "Anna"}})
+	if errors.Cause(err) != ErrPersonaPictureRequired {
+		t.Errorf("validation error expected, got %v", err)
+	}
+	if len(c.created) != 0 {
+		t.Errorf("nothing should be created for invalid personas: %v", c.created)
+	}
+}
@@ -0,0 +1,103 @@
+package messenger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestPersonaValidate(t *testing.T) {
+	tests := []struct {
+		persona Persona
+		err     error
+	}{
+		{Persona{Name: "Agent", ProfilePictureURL: "https://example.com/a.png"}, nil},
+		{Persona{ProfilePictureURL: "https://example.com/a.png"}, ErrPersonaNameRequired},
+		{Persona{Name: "Agent"}, ErrPersonaPictureRequired},
+		{Persona{Name: "Agent", ProfilePictureURL: "/a.png"}, ErrPersonaPictureURLNotValid},
+	}
+	for _, test := range tests {
+		if err := test.persona.Validate(); err != test.err {
+			t.Errorf("Validate(%+v) = %v, expected %v", test.persona, err, test.err)
+		}
+	}
+}
+
+func TestCreatePersona(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		var persona Persona
+		if err := json.NewDecoder(r.Body).Decode(&persona); err != nil {
+			t.Error(err)
+			return
+		}
+		if persona.Name != "Agent" || persona.ProfilePictureURL != "https://example.com/a.png" {
+			t.Errorf("unexpected persona: %+v", persona)
+		}
+		w.Write([]byte(`{"id":"42"}`))
+	})
+
+	response, err := c.CreatePersona("token", json.RawMessage(`{"name":"Agent","profile_picture_url":"https://example.com/a.png"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ID != "42" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	persona, err := c.AddPersona("token", Persona{Name: "Agent", ProfilePictureURL: "https://example.com/a.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if persona.ID != "42" || persona.Name != "Agent" {
+		t.Errorf("unexpected persona: %+v", persona)
+	}
+
+	if _, err := c.AddPersona("token", Persona{Name: "Agent"}); errors.Cause(err) != ErrPersonaPictureRequired {
+		t.Errorf("validation error expected, got %v", err)
+	}
+}
+
+func TestPersonasPaging(t *testing.T) {
+	var serverURL string
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprintf(w, `{"data":[{"id":"1","name":"A"},{"id":"2","name":"B"}],"paging":{"cursors":{"after":"c1"},"next":"%s/%s/me/personas?access_token=token&after=c1"}}`, serverURL, GraphAPIVersion)
+		case "c1":
+			w.Write([]byte(`{"data":[{"id":"3","name":"C"}],"paging":{"cursors":{"before":"c1"}}}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	})
+	serverURL = GraphAPI
+
+	personas, err := c.Personas("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(personas) != 3 || personas[2].ID != "3" || personas[0].Name != "A" {
+		t.Errorf("unexpected personas: %+v", personas)
+	}
+}
+
+func TestPersonaGraphErrors(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"Unsupported delete request","code":100}}`))
+	})
+
+	if err := c.DeletePersona("token", "42"); !isGraphErrorCode(err, ErrorCodeInvalidParameter) {
+		t.Errorf("graph api error expected, got %v", err)
+	}
+	if _, err := c.GetPersona("token", "42"); !isGraphErrorCode(err, ErrorCodeInvalidParameter) {
+		t.Errorf("graph api error expected, got %v", err)
+	}
+}
+
+func isGraphErrorCode(err error, code int) bool {
+	graphErr, ok := errors.Cause(err).(Error)
+	return ok && graphErr.Code == code
+}
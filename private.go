@@ -1,9 +1,15 @@
 package messenger
 
+import "errors"
+
 const (
 	PrivateReplyPath string = "private_replies"
+	MessagesPath     string = "me/messages"
 )
 
+// ErrPrivateReplyRecipient is returned if the recipient of a private reply is not a single comment or visitor post
+var ErrPrivateReplyRecipient = errors.New("private reply recipient has to be either a comment_id or a post_id")
+
 // PrivateReply represents the private reply message structure.
 type PrivateReply struct {
 	ID      string `json:"id,omitempty"` // The ID of the Page Comment or Visitor Post that you are replying to.
@@ -14,4 +20,20 @@ type PrivateReply struct {
 type PrivateReplyResponse struct {
 	ID     string `json:"id"`      // The ID of the newly created Message.
 	UserID string `json:"user_id"` // The app_scoped_user_id of the visitor.
+
+	RecipientID string `json:"recipient_id,omitempty"` // The page scoped ID of the visitor, if sent with the Send API.
+	MessageID   string `json:"message_id,omitempty"`   // The ID of the message, if sent with the Send API.
+}
+
+// IsPrivateReply reports whether the recipient is a comment or a visitor post
+func (r Recipient) IsPrivateReply() bool {
+	return r.CommentID != "" || r.PostID != ""
+}
+
+// validatePrivateReply checks that exactly one of CommentID and PostID is set
+func (r Recipient) validatePrivateReply() error {
+	if (r.CommentID == "") == (r.PostID == "") || r.ID != "" || r.PhoneNumber != "" {
+		return ErrPrivateReplyRecipient
+	}
+	return nil
 }
@@ -0,0 +1,60 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSendPrivateReplyMessage(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+GraphAPIVersion+"/"+MessagesPath {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var q MessageQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			t.Error(err)
+			return
+		}
+		if q.Recipient.CommentID != "123_456" || q.Message.Text != "Thanks!" || len(q.Message.QuickReplies) != 1 {
+			t.Errorf("unexpected query: %+v", q)
+		}
+		w.Write([]byte(`{"recipient_id":"789","message_id":"m_1"}`))
+	})
+
+	q, err := NewMessageBuilder().ToComment("123_456").Text("Thanks!").TextQuickReply("Track order", "TRACK").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.SendPrivateReplyMessage("token", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.RecipientID != "789" || resp.MessageID != "m_1" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestSendPrivateReplyMessageErrors(t *testing.T) {
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"This comment has already been replied to","code":10900}}`))
+	})
+
+	q, _ := NewMessageBuilder().To("789").Text("Thanks!").Build()
+	if _, err := c.SendPrivateReplyMessage("token", q); err != ErrPrivateReplyRecipient {
+		t.Errorf("recipient error expected, got %v", err)
+	}
+	q.Recipient = Recipient{CommentID: "1", PostID: "2"}
+	if _, err := c.SendPrivateReplyMessage("token", q); err != ErrPrivateReplyRecipient {
+		t.Errorf("recipient error expected, got %v", err)
+	}
+
+	q.Recipient = Recipient{PostID: "2"}
+	if _, err := c.SendPrivateReplyMessage("token", q); !isGraphErrorCode(err, 10900) {
+		t.Errorf("graph api error expected, got %v", err)
+	}
+	if _, err := c.SendPrivateReply("2", "token", "Thanks!"); !isGraphErrorCode(err, 10900) {
+		t.Errorf("graph api error expected from the legacy edge, got %v", err)
+	}
+}
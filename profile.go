@@ -1,5 +1,14 @@
 package messenger
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 type (
 	// Field represents a field in facebook graph API
 	Field string
@@ -28,6 +37,12 @@ const (
 	Gender         Field = "gender"
 )
 
+// DefaultProfileFields are the fields fetched if no fields are requested, they are available without extra permissions
+var DefaultProfileFields = []Field{Name, FirstName, LastName, ProfilePicture}
+
+// RestrictedProfileFields are the fields requiring extra permissions, they are dropped first if a profile request is denied
+var RestrictedProfileFields = []Field{Locale, Timezone, Gender}
+
 // Profile struct holds data associated with Facebook profile
 type Profile struct {
 	Name           string  `json:"name"`
@@ -37,9 +52,110 @@ type Profile struct {
 	Locale         string  `json:"locale,omitempty"`
 	Timezone       float64 `json:"timezone,omitempty"`
 	Gender         string  `json:"gender,omitempty"`
+	// FetchedFields lists the fields the profile was fetched with, the dropped fields excluded
+	FetchedFields []Field `json:"-"`
+	// DroppedFields lists the requested fields which were denied and left out of the profile
+	DroppedFields []Field `json:"-"`
 }
 
-type accountLinking struct {
-	//Recipient is Page Scoped ID
-	Recipient string `json:"recipient"`
+// Location returns the user's time zone as a fixed offset location,
+// nil if the timezone field wasn't fetched because it wasn't requested or it was dropped.
+func (p Profile) Location() *time.Location {
+	if !Fields(p.FetchedFields).contains(Timezone) {
+		return nil
+	}
+
+	offset := int(p.Timezone * 3600)
+	sign, abs := "+", offset
+	if offset < 0 {
+		sign, abs = "-", -offset
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%s%02d:%02d", sign, abs/3600, abs%3600/60), offset)
+}
+
+// LanguageTag returns the user's locale as a BCP 47 language tag, e.g. en-US for en_US
+func (p Profile) LanguageTag() string {
+	return strings.Replace(p.Locale, "_", "-", -1)
+}
+
+// Dropped reports whether the field was denied and left out of the profile
+func (p Profile) Dropped(field Field) bool {
+	for _, f := range p.DroppedFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileErrors maps the user ids to the errors occurred while fetching their profiles
+type ProfileErrors map[string]error
+
+// Error ...
+func (e ProfileErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for id, err := range e {
+		messages = append(messages, id+": "+err.Error())
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
+
+// withoutForbiddenFields splits the fields into the remaining and the forbidden ones if err is a Graph API error denying some of them.
+// The fields named in the error message are forbidden, or the restricted fields if the error is a permission error without field names.
+func withoutForbiddenFields(fields []Field, err error) (remaining, forbidden []Field) {
+	graphErr, ok := errors.Cause(err).(Error)
+	if !ok || (!IsPermissionError(graphErr) && graphErr.Code != ErrorCodeInvalidParameter) {
+		return fields, nil
+	}
+
+	message := strings.ToLower(graphErr.Message)
+	for _, f := range fields {
+		if containsField(message, string(f)) {
+			forbidden = append(forbidden, f)
+		} else {
+			remaining = append(remaining, f)
+		}
+	}
+	if len(forbidden) > 0 || !IsPermissionError(graphErr) {
+		return remaining, forbidden
+	}
+
+	remaining = nil
+	for _, f := range fields {
+		if Fields(RestrictedProfileFields).contains(f) {
+			forbidden = append(forbidden, f)
+		} else {
+			remaining = append(remaining, f)
+		}
+	}
+	return remaining, forbidden
+}
+
+// containsField reports whether the field name is in the message as a whole word, e.g. name is not found in first_name
+func containsField(message, field string) bool {
+	isWordChar := func(b byte) bool {
+		return b == '_' || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9')
+	}
+	for i := 0; i+len(field) <= len(message); {
+		n := strings.Index(message[i:], field)
+		if n < 0 {
+			return false
+		}
+		start, end := i+n, i+n+len(field)
+		if (start == 0 || !isWordChar(message[start-1])) && (end == len(message) || !isWordChar(message[end])) {
+			return true
+		}
+		i = start + 1
+	}
+	return false
+}
+
+func (f Fields) contains(field Field) bool {
+	for _, i := range f {
+		if i == field {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,218 @@
+package messenger
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Profile cache defaults
+const (
+	DefaultProfileCacheTTL         = time.Hour
+	DefaultProfileCacheNegativeTTL = 10 * time.Minute
+	DefaultProfileCacheSize        = 10000
+)
+
+// ProfileCacheOptions configures a ProfileCache, zero values are replaced by the defaults
+type ProfileCacheOptions struct {
+	// TTL is how long a fetched profile is kept
+	TTL time.Duration
+	// NegativeTTL is how long a permission error is kept, so the profile isn't requested again and again
+	NegativeTTL time.Duration
+	// Size is the maximum number of cached profiles, the least recently used one is evicted first
+	Size int
+	// Fields are the requested profile fields, the default fields of GetProfile are used if empty
+	Fields []Field
+}
+
+// ProfileCache fetches the user profiles of a page and keeps them in memory.
+// Concurrent lookups of the same user share one request, and permission errors are cached as well.
+type ProfileCache struct {
+	controller  Controller
+	accessToken string
+	options     ProfileCacheOptions
+	now         func() time.Time
+
+	mu       sync.Mutex
+	lru      *list.List
+	entries  map[string]*list.Element
+	inflight map[string]*profileCall
+}
+
+type profileCacheEntry struct {
+	userID  string
+	profile Profile
+	err     error
+	expires time.Time
+}
+
+type profileCall struct {
+	done    chan struct{}
+	profile Profile
+	err     error
+}
+
+// NewProfileCache returns a ProfileCache fetching the profiles with the page's access token
+func NewProfileCache(c Controller, accessToken string, options ProfileCacheOptions) *ProfileCache {
+	if options.TTL <= 0 {
+		options.TTL = DefaultProfileCacheTTL
+	}
+	if options.NegativeTTL <= 0 {
+		options.NegativeTTL = DefaultProfileCacheNegativeTTL
+	}
+	if options.Size <= 0 {
+		options.Size = DefaultProfileCacheSize
+	}
+	return &ProfileCache{
+		controller:  c,
+		accessToken: accessToken,
+		options:     options,
+		now:         time.Now,
+		lru:         list.New(),
+		entries:     make(map[string]*list.Element),
+		inflight:    make(map[string]*profileCall),
+	}
+}
+
+// Profile returns the profile of the user, fetching it if it's not cached or expired
+func (c *ProfileCache) Profile(userID string) (Profile, error) {
+	c.mu.Lock()
+	if entry, ok := c.get(userID); ok {
+		c.mu.Unlock()
+		return entry.profile, entry.err
+	}
+	if call, ok := c.inflight[userID]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.profile, call.err
+	}
+	call := &profileCall{done: make(chan struct{})}
+	c.inflight[userID] = call
+	c.mu.Unlock()
+
+	call.profile, call.err = c.controller.GetProfile(userID, c.accessToken, "", c.options.Fields...)
+
+	c.mu.Lock()
+	delete(c.inflight, userID)
+	c.store(userID, call.profile, call.err)
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.profile, call.err
+}
+
+// Profiles returns the profiles of the users. The cached profiles are returned from the cache,
+// the rest are fetched with batch requests. Profiles which couldn't be fetched are missing from the result,
+// their errors are returned in ProfileErrors.
+func (c *ProfileCache) Profiles(userIDs ...string) (map[string]Profile, error) {
+	profiles := make(map[string]Profile, len(userIDs))
+	profileErrs := make(ProfileErrors)
+	var missing []string
+
+	c.mu.Lock()
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entry, ok := c.get(id)
+		switch {
+		case !ok:
+			missing = append(missing, id)
+		case entry.err != nil:
+			profileErrs[id] = entry.err
+		default:
+			profiles[id] = entry.profile
+		}
+	}
+	c.mu.Unlock()
+
+	if len(missing) > 0 {
+		fetched, err := c.controller.GetProfiles(c.accessToken, missing, c.options.Fields...)
+		fetchErrs, ok := err.(ProfileErrors)
+		if err != nil && !ok {
+			return profiles, err
+		}
+
+		c.mu.Lock()
+		for id, profile := range fetched {
+			c.store(id, profile, nil)
+			profiles[id] = profile
+		}
+		for id, err := range fetchErrs {
+			c.store(id, Profile{}, err)
+			profileErrs[id] = err
+		}
+		c.mu.Unlock()
+	}
+
+	if len(profileErrs) > 0 {
+		return profiles, profileErrs
+	}
+	return profiles, nil
+}
+
+// Invalidate removes the user's profile from the cache
+func (c *ProfileCache) Invalidate(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[userID]; ok {
+		c.lru.Remove(e)
+		delete(c.entries, userID)
+	}
+}
+
+// Len returns the number of cached profiles, expired ones included
+func (c *ProfileCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// get returns the entry of the user if it's cached and not expired, c.mu has to be held
+func (c *ProfileCache) get(userID string) (*profileCacheEntry, bool) {
+	e, ok := c.entries[userID]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*profileCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.lru.Remove(e)
+		delete(c.entries, userID)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return entry, true
+}
+
+// store caches the profile or the permission error of the user, other errors are not cached. c.mu has to be held.
+func (c *ProfileCache) store(userID string, profile Profile, err error) {
+	ttl := c.options.TTL
+	if err != nil {
+		if !IsPermissionError(err) {
+			return
+		}
+		ttl = c.options.NegativeTTL
+	}
+
+	entry := &profileCacheEntry{
+		userID:  userID,
+		profile: profile,
+		err:     err,
+		expires: c.now().Add(ttl),
+	}
+	if e, ok := c.entries[userID]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[userID] = c.lru.PushFront(entry)
+
+	for c.lru.Len() > c.options.Size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*profileCacheEntry).userID)
+	}
+}
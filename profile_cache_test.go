@@ -0,0 +1,176 @@
+package messenger
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// profileController is a Controller answering the profile requests with the profiles map
+type profileController struct {
+	Controller
+	profiles map[string]Profile
+	errs     map[string]error
+	calls    int32
+	batches  [][]string
+	delay    time.Duration
+}
+
+func (c *profileController) GetProfile(userID, accessToken, url string, fields ...Field) (Profile, error) {
+	atomic.AddInt32(&c.calls, 1)
+	time.Sleep(c.delay)
+	if err, ok := c.errs[userID]; ok {
+		return Profile{}, errors.Wrap(err, "Error occured")
+	}
+	return c.profiles[userID], nil
+}
+
+func (c *profileController) GetProfiles(accessToken string, userIDs []string, fields ...Field) (map[string]Profile, error) {
+	c.batches = append(c.batches, userIDs)
+	profiles := make(map[string]Profile)
+	profileErrs := make(ProfileErrors)
+	for _, id := range userIDs {
+		if err, ok := c.errs[id]; ok {
+			profileErrs[id] = err
+			continue
+		}
+		profiles[id] = c.profiles[id]
+	}
+	if len(profileErrs) > 0 {
+		return profiles, profileErrs
+	}
+	return profiles, nil
+}
+
+func TestProfileCacheTTL(t *testing.T) {
+	c := &profileController{profiles: map[string]Profile{"1": {Name: "Alice"}}}
+	cache := NewProfileCache(c, "token", ProfileCacheOptions{TTL: time.Minute})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		p, err := cache.Profile("1")
+		if err != nil || p.Name != "Alice" {
+			t.Errorf("unexpected profile: %+v, %v", p, err)
+		}
+	}
+	if c.calls != 1 {
+		t.Errorf("profile fetched %d times, expected once", c.calls)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := cache.Profile("1"); err != nil {
+		t.Error(err)
+	}
+	if c.calls != 2 {
+		t.Errorf("expired profile not fetched again, calls: %d", c.calls)
+	}
+}
+
+func TestProfileCacheLRU(t *testing.T) {
+	c := &profileController{profiles: map[string]Profile{"1": {Name: "A"}, "2": {Name: "B"}, "3": {Name: "C"}}}
+	cache := NewProfileCache(c, "token", ProfileCacheOptions{Size: 2})
+
+	cache.Profile("1")
+	cache.Profile("2")
+	cache.Profile("1")
+	cache.Profile("3")
+	if cache.Len() != 2 {
+		t.Errorf("unexpected cache size: %d", cache.Len())
+	}
+
+	c.calls = 0
+	cache.Profile("1")
+	cache.Profile("3")
+	if c.calls != 0 {
+		t.Errorf("recently used profiles evicted, calls: %d", c.calls)
+	}
+	cache.Profile("2")
+	if c.calls != 1 {
+		t.Errorf("least recently used profile not evicted, calls: %d", c.calls)
+	}
+}
+
+func TestProfileCacheDeduplicatesRequests(t *testing.T) {
+	c := &profileController{profiles: map[string]Profile{"1": {Name: "Alice"}}, delay: 50 * time.Millisecond}
+	cache := NewProfileCache(c, "token", ProfileCacheOptions{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p, err := cache.Profile("1"); err != nil || p.Name != "Alice" {
+				t.Errorf("unexpected profile: %+v, %v", p, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.calls != 1 {
+		t.Errorf("concurrent lookups sent %d requests, expected 1", c.calls)
+	}
+}
+
+func TestProfileCacheNegativeCaching(t *testing.T) {
+	c := &profileController{errs: map[string]error{
+		"forbidden": Error{Code: 230, Message: "Requires pages_messaging permission"},
+		"broken":    Error{Code: 2, Message: "Service temporarily unavailable"},
+	}}
+	cache := NewProfileCache(c, "token", ProfileCacheOptions{NegativeTTL: time.Minute})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Profile("forbidden"); !IsPermissionError(err) {
+			t.Errorf("permission error expected, got %v", err)
+		}
+	}
+	if c.calls != 1 {
+		t.Errorf("permission error not cached, calls: %d", c.calls)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Profile("forbidden")
+	if c.calls != 2 {
+		t.Errorf("expired permission error not refetched, calls: %d", c.calls)
+	}
+
+	c.calls = 0
+	cache.Profile("broken")
+	cache.Profile("broken")
+	if c.calls != 2 {
+		t.Errorf("non permission error cached, calls: %d", c.calls)
+	}
+}
+
+func TestProfileCacheProfiles(t *testing.T) {
+	c := &profileController{
+		profiles: map[string]Profile{"1": {Name: "A"}, "2": {Name: "B"}, "3": {Name: "C"}},
+		errs:     map[string]error{"4": Error{Code: 10, Message: "Permission denied"}},
+	}
+	cache := NewProfileCache(c, "token", ProfileCacheOptions{})
+	cache.Profile("1")
+
+	profiles, err := cache.Profiles("1", "2", "3", "2", "4")
+	profileErrs, ok := err.(ProfileErrors)
+	if !ok || len(profileErrs) != 1 || !IsPermissionError(profileErrs["4"]) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(profiles) != 3 || profiles["1"].Name != "A" || profiles["3"].Name != "C" {
+		t.Errorf("unexpected profiles: %v", profiles)
+	}
+	if len(c.batches) != 1 || len(c.batches[0]) != 3 {
+		t.Errorf("only the missing profiles should be fetched in one batch: %v", c.batches)
+	}
+
+	if _, err := cache.Profiles("2", "3", "4"); err == nil {
+		t.Error("cached permission error expected")
+	}
+	if len(c.batches) != 1 {
+		t.Errorf("cached profiles fetched again: %v", c.batches)
+	}
+}
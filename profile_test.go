@@ -0,0 +1,64 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfileLocation(t *testing.T) {
+	tests := []struct {
+		timezone float64
+		name     string
+		offset   int
+	}{
+		{0, "UTC+00:00", 0},
+		{2, "UTC+02:00", 2 * 3600},
+		{5.5, "UTC+05:30", 5*3600 + 1800},
+		{-3.5, "UTC-03:30", -(3*3600 + 1800)},
+	}
+	for _, test := range tests {
+		loc := Profile{Timezone: test.timezone, FetchedFields: []Field{Timezone}}.Location()
+		name, offset := time.Date(2020, 1, 1, 0, 0, 0, 0, loc).Zone()
+		if name != test.name || offset != test.offset {
+			t.Errorf("Location(%v) = %s %d, expected %s %d", test.timezone, name, offset, test.name, test.offset)
+		}
+	}
+
+	if loc := (Profile{FetchedFields: DefaultProfileFields}).Location(); loc != nil {
+		t.Errorf("timezone wasn't requested, got %s", loc)
+	}
+}
+
+func TestProfileLanguageTag(t *testing.T) {
+	if tag := (Profile{Locale: "en_US"}).LanguageTag(); tag != "en-US" {
+		t.Errorf("unexpected language tag: %s", tag)
+	}
+	if tag := (Profile{}).LanguageTag(); tag != "" {
+		t.Errorf("unexpected language tag: %s", tag)
+	}
+}
+
+func TestWithoutForbiddenFields(t *testing.T) {
+	fields := []Field{Name, FirstName, Locale, Timezone}
+	tests := []struct {
+		err       error
+		forbidden []Field
+	}{
+		{Error{Code: 100, Message: "Tried accessing nonexisting field (name)"}, []Field{Name}},
+		{Error{Code: 230, Message: "Requires permission"}, []Field{Locale, Timezone}},
+		{Error{Code: 100, Message: "Unsupported get request"}, nil},
+		{Error{Code: 2, Message: "Service unavailable for locale"}, nil},
+	}
+	for _, test := range tests {
+		remaining, forbidden := withoutForbiddenFields(fields, test.err)
+		if len(forbidden) != len(test.forbidden) || len(remaining)+len(forbidden) != len(fields) {
+			t.Errorf("withoutForbiddenFields(%v) = %v, %v, expected forbidden %v", test.err, remaining, forbidden, test.forbidden)
+			continue
+		}
+		for i := range forbidden {
+			if forbidden[i] != test.forbidden[i] {
+				t.Errorf("withoutForbiddenFields(%v) forbidden %v, expected %v", test.err, forbidden, test.forbidden)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package messenger
 
 import (
+	"encoding/json"
 	"errors"
 )
 
@@ -8,19 +9,71 @@ import (
 const (
 	DefaultLocale             = "default"
 	PersistentMenuButtonLimit = 3
+	PersistentMenuTitleLimit  = 30
+	GreetingTextLengthLimit   = 160
+	WhitelistedDomainsLimit   = 50
+	IceBreakersLimit          = 4
+	IceBreakerQuestionLimit   = 80
+	HomeURLWebviewHeightRatio = "tall"
 	CTATypePostback           = "postback"
 	CTATypeURL                = "web_url"
 	CTATypeNested             = "nested"
 	MessengerSettingsPath     = "me/messenger_profile"
 )
 
+// SettingsField is the name of a messenger profile property
+type SettingsField string
+
+// SettingsField* are the messenger profile properties of Settings
+const (
+	SettingsFieldAccountLinkingURL  SettingsField = "account_linking_url"
+	SettingsFieldGetStarted         SettingsField = "get_started"
+	SettingsFieldGreeting           SettingsField = "greeting"
+	SettingsFieldPersistentMenu     SettingsField = "persistent_menu"
+	SettingsFieldWhitelistedDomains SettingsField = "whitelisted_domains"
+	SettingsFieldIceBreakers        SettingsField = "ice_breakers"
+	SettingsFieldHomeURL            SettingsField = "home_url"
+	SettingsFieldPaymentSettings    SettingsField = "payment_settings"
+)
+
+// String ...
+func (f SettingsField) String() string {
+	return string(f)
+}
+
+// SettingsFields lists all properties of Settings
+var SettingsFields = []SettingsField{
+	SettingsFieldAccountLinkingURL,
+	SettingsFieldGetStarted,
+	SettingsFieldGreeting,
+	SettingsFieldPersistentMenu,
+	SettingsFieldWhitelistedDomains,
+	SettingsFieldIceBreakers,
+	SettingsFieldHomeURL,
+	SettingsFieldPaymentSettings,
+}
+
 // Settings is the implementation of https://developers.facebook.com/docs/messenger-platform/reference/messenger-profile-api
 // https://developers.facebook.com/docs/messenger-platform/reference/messenger-profile-api/account-linking-url
 type Settings struct {
-	AccountLinkingURL *string          `json:"account_linking_url,omitempty"`
-	GetStarted        *GetStarted      `json:"get_started,omitempty"`
-	Greetings         []Greeting       `json:"greeting,omitempty"`
-	PersistentMenu    []PersistentMenu `json:"persistent_menu,omitempty"`
+	AccountLinkingURL  *string          `json:"account_linking_url,omitempty"`
+	GetStarted         *GetStarted      `json:"get_started,omitempty"`
+	Greetings          []Greeting       `json:"greeting,omitempty"`
+	PersistentMenu     []PersistentMenu `json:"persistent_menu,omitempty"`
+	WhitelistedDomains []string         `json:"whitelisted_domains,omitempty"`
+	IceBreakers        IceBreakerList   `json:"ice_breakers,omitempty"`
+	HomeURL            *HomeURL         `json:"home_url,omitempty"`
+	PaymentSettings    *PaymentSettings `json:"payment_settings,omitempty"`
+}
+
+// settingsResponse represents the response of the messenger profile GET request
+type settingsResponse struct {
+	Data []Settings `json:"data"`
+}
+
+// deleteSettings represents the body of the messenger profile DELETE request
+type deleteSettings struct {
+	Fields []SettingsField `json:"fields"`
 }
 
 // AddMenu Append a new PersistentMenu element to settings
@@ -66,6 +119,59 @@ func (s *Settings) UpdatePersistentMenus(p []PersistentMenu) {
 	s.PersistentMenu = p
 }
 
+// AddWhitelistedDomain appends a new domain to the whitelisted domains or return error if the domain is invalid
+func (s *Settings) AddWhitelistedDomain(domain string) error {
+	if err := ValidateWhitelistedDomain(domain); err != nil {
+		return err
+	}
+	if len(s.WhitelistedDomains) >= WhitelistedDomainsLimit {
+		return errors.New("Whitelisted domain limit exceeded")
+	}
+	s.WhitelistedDomains = append(s.WhitelistedDomains, domain)
+
+	return nil
+}
+
+// AddIceBreaker appends a new question to the ice breakers of the locale or return error if limit exceeded
+// Use DefaultLocale for the ice breakers shown to every user
+func (s *Settings) AddIceBreaker(locale, question, payload string) error {
+	for i := range s.IceBreakers {
+		if s.IceBreakers[i].Locale == locale {
+			return s.IceBreakers[i].AddIceBreaker(question, payload)
+		}
+	}
+
+	s.IceBreakers = append(s.IceBreakers, IceBreakers{Locale: locale})
+	return s.IceBreakers[len(s.IceBreakers)-1].AddIceBreaker(question, payload)
+}
+
+// SetHomeURL add/update the chat extension home url of the settings
+func (s *Settings) SetHomeURL(homeURL string, inTest bool) {
+	s.HomeURL = &HomeURL{
+		URL:                homeURL,
+		WebviewHeightRatio: HomeURLWebviewHeightRatio,
+		InTest:             inTest,
+	}
+}
+
+// SetPaymentSettings add/update the payment settings
+func (s *Settings) SetPaymentSettings(p PaymentSettings) {
+	s.PaymentSettings = &p
+}
+
+// AddIceBreaker appends a new question to the ice breakers or return error if limit exceeded
+func (i *IceBreakers) AddIceBreaker(question, payload string) error {
+	if len(i.CTAs) >= IceBreakersLimit {
+		return errors.New("Ice breaker limit exceeded")
+	}
+	i.CTAs = append(i.CTAs, IceBreaker{
+		Question: question,
+		Payload:  payload,
+	})
+
+	return nil
+}
+
 // AddCTA appends a new CTA element to the PersistentMenu or return error if limit exceeded
 func (p *PersistentMenu) AddCTA(c CTA) error {
 	if len(p.CTAs) >= PersistentMenuButtonLimit {
@@ -86,38 +192,6 @@ func (c *CTA) AddCTA(newCTA CTA) error {
 	return nil
 }
 
-// Validate validates the persistent menu
-// Max menu elem: 3 element / level
-// Max depth: 3 level
-func (p *PersistentMenu) Validate() error {
-	if p.CTAs == nil {
-		return nil
-	}
-
-	// lvl1 element count check
-	if len(p.CTAs) > PersistentMenuButtonLimit {
-		return errors.New("Menu CTA limit exceeded")
-	}
-
-	for _, lvl1 := range p.CTAs {
-		if lvl1.CTAs != nil {
-			for _, lvl2 := range lvl1.CTAs {
-				if lvl2.CTAs != nil {
-					if len(lvl2.CTAs) > PersistentMenuButtonLimit {
-						return errors.New("Menu CTA limit exceeded in lvl2")
-					}
-					for _, lvl3 := range lvl2.CTAs {
-						if lvl3.CTAs != nil {
-							return errors.New("Maximum menu depth is 3 lvl")
-						}
-					}
-				}
-			}
-		}
-	}
-	return nil
-}
-
 // GetStarted is the implementation of https://developers.facebook.com/docs/messenger-platform/reference/messenger-profile-api/get-started-button
 type GetStarted struct {
 	Payload string `json:"payload"`
@@ -145,3 +219,73 @@ type CTA struct {
 	Payload            *string `json:"payload,omitempty"`
 	CTAs               []CTA   `json:"call_to_actions,omitempty"`
 }
+
+// IceBreaker is a question the user can send to start a conversation
+type IceBreaker struct {
+	Question string `json:"question"`
+	Payload  string `json:"payload"`
+}
+
+// IceBreakers is the list of ice breakers of a locale
+// https://developers.facebook.com/docs/messenger-platform/reference/messenger-profile-api/ice-breakers
+type IceBreakers struct {
+	Locale string       `json:"locale"`
+	CTAs   []IceBreaker `json:"call_to_actions"`
+}
+
+// IceBreakerList holds the localized ice breakers
+type IceBreakerList []IceBreakers
+
+// UnmarshalJSON decodes both the localized and the legacy, not localized ice breaker formats.
+// Legacy ice breakers are returned with DefaultLocale.
+func (l *IceBreakerList) UnmarshalJSON(b []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+
+	var list IceBreakerList
+	var legacy []IceBreaker
+	for _, item := range items {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(item, &fields); err != nil {
+			return err
+		}
+
+		if _, ok := fields["question"]; ok {
+			var i IceBreaker
+			if err := json.Unmarshal(item, &i); err != nil {
+				return err
+			}
+			legacy = append(legacy, i)
+			continue
+		}
+
+		var i IceBreakers
+		if err := json.Unmarshal(item, &i); err != nil {
+			return err
+		}
+		list = append(list, i)
+	}
+
+	if legacy != nil {
+		list = append(list, IceBreakers{Locale: DefaultLocale, CTAs: legacy})
+	}
+	*l = list
+	return nil
+}
+
+// HomeURL is the implementation of https://developers.facebook.com/docs/messenger-platform/reference/messenger-profile-api/home-url
+type HomeURL struct {
+	URL                string `json:"url"`
+	WebviewHeightRatio string `json:"webview_height_ratio"`
+	WebviewShareButton string `json:"webview_share_button,omitempty"`
+	InTest             bool   `json:"in_test"`
+}
+
+// PaymentSettings is the implementation of https://developers.facebook.com/docs/messenger-platform/reference/messenger-profile-api/payment-settings
+type PaymentSettings struct {
+	PrivacyURL string   `json:"privacy_url,omitempty"`
+	PublicKey  string   `json:"public_key,omitempty"`
+	TestUsers  []string `json:"test_users,omitempty"`
+}
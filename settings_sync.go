@@ -0,0 +1,177 @@
+package messenger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SettingsChangeAction describes how a messenger profile property has to be changed
+type SettingsChangeAction string
+
+// SettingsChange* are the actions of a settings plan
+const (
+	SettingsChangeAdd    SettingsChangeAction = "+"
+	SettingsChangeUpdate SettingsChangeAction = "~"
+	SettingsChangeDelete SettingsChangeAction = "-"
+)
+
+// SettingsChange is a change of a single messenger profile property
+type SettingsChange struct {
+	Field   SettingsField
+	Action  SettingsChangeAction
+	Current json.RawMessage
+	Desired json.RawMessage
+}
+
+// String returns the change in a diff like format
+func (c SettingsChange) String() string {
+	switch c.Action {
+	case SettingsChangeAdd:
+		return fmt.Sprintf("%s %s: %s", c.Action, c.Field, c.Desired)
+	case SettingsChangeDelete:
+		return fmt.Sprintf("%s %s: %s", c.Action, c.Field, c.Current)
+	}
+	return fmt.Sprintf("%s %s: %s => %s", c.Action, c.Field, c.Current, c.Desired)
+}
+
+// SettingsPlan is the list of changes needed to turn the current settings into the desired ones
+type SettingsPlan []SettingsChange
+
+// String returns the changes of the plan, one per line
+func (p SettingsPlan) String() string {
+	if len(p) == 0 {
+		return "no changes\n"
+	}
+	var b strings.Builder
+	for _, c := range p {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// DiffSettings compares the settings property by property and returns the changes turning current into desired.
+// Properties not set in desired are deleted. Greetings, persistent menus and ice breakers are compared regardless of their locale order.
+func DiffSettings(current, desired Settings) (SettingsPlan, error) {
+	currentFields, err := settingsFields(current)
+	if err != nil {
+		return nil, errors.Wrap(err, "DiffSettings/current")
+	}
+	desiredFields, err := settingsFields(desired)
+	if err != nil {
+		return nil, errors.Wrap(err, "DiffSettings/desired")
+	}
+
+	var plan SettingsPlan
+	for _, field := range SettingsFields {
+		cur, hasCurrent := currentFields[field]
+		des, hasDesired := desiredFields[field]
+
+		change := SettingsChange{Field: field, Current: cur, Desired: des}
+		switch {
+		case hasDesired && !hasCurrent:
+			change.Action = SettingsChangeAdd
+		case !hasDesired && hasCurrent:
+			change.Action = SettingsChangeDelete
+		case hasDesired && hasCurrent && !bytes.Equal(cur, des):
+			change.Action = SettingsChangeUpdate
+		default:
+			continue
+		}
+		plan = append(plan, change)
+	}
+	return plan, nil
+}
+
+// PlanPageSettings fetches the messenger page's settings and returns the changes needed to reach the desired settings.
+func PlanPageSettings(c Controller, accessToken string, desired Settings) (SettingsPlan, error) {
+	current, err := c.GetPageSettings(accessToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "PlanPageSettings")
+	}
+	return DiffSettings(current, desired)
+}
+
+// Apply sends the changes of the plan: one update request for the added and updated properties
+// and one delete request for the deleted ones. Nothing is sent for an empty plan.
+func (p SettingsPlan) Apply(c Controller, accessToken string) error {
+	update := make(map[SettingsField]json.RawMessage)
+	var deleted []SettingsField
+	for _, change := range p {
+		if change.Action == SettingsChangeDelete {
+			deleted = append(deleted, change.Field)
+			continue
+		}
+		update[change.Field] = change.Desired
+	}
+
+	if len(update) > 0 {
+		payload, err := json.Marshal(update)
+		if err != nil {
+			return errors.Wrapf(err, "SettingsPlan.Apply/json.Marshal(%v)", update)
+		}
+		if err := c.UpdatePageSettings(accessToken, payload); err != nil {
+			return errors.Wrap(err, "SettingsPlan.Apply")
+		}
+	}
+
+	if len(deleted) > 0 {
+		if err := c.DeletePageSettingsFields(accessToken, deleted...); err != nil {
+			return errors.Wrap(err, "SettingsPlan.Apply")
+		}
+	}
+	return nil
+}
+
+// SyncPageSettings makes the messenger page's settings equal to the desired settings, changing only the differing properties.
+// The planned changes are printed to w if it's not nil. In dry run mode the changes are not applied.
+func SyncPageSettings(c Controller, accessToken string, desired Settings, dryRun bool, w io.Writer) (SettingsPlan, error) {
+	plan, err := PlanPageSettings(c, accessToken, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	if w != nil {
+		if _, err := io.WriteString(w, plan.String()); err != nil {
+			return plan, errors.Wrap(err, "SyncPageSettings/io.WriteString")
+		}
+	}
+
+	if dryRun {
+		return plan, nil
+	}
+	return plan, plan.Apply(c, accessToken)
+}
+
+// settingsFields returns the set properties of the settings in their JSON form, normalized for comparison.
+func settingsFields(s Settings) (map[SettingsField]json.RawMessage, error) {
+	s.Greetings = append([]Greeting(nil), s.Greetings...)
+	sort.SliceStable(s.Greetings, func(i, j int) bool {
+		return s.Greetings[i].Locale < s.Greetings[j].Locale
+	})
+	s.PersistentMenu = append([]PersistentMenu(nil), s.PersistentMenu...)
+	sort.SliceStable(s.PersistentMenu, func(i, j int) bool {
+		return s.PersistentMenu[i].Locale < s.PersistentMenu[j].Locale
+	})
+	s.IceBreakers = append(IceBreakerList(nil), s.IceBreakers...)
+	sort.SliceStable(s.IceBreakers, func(i, j int) bool {
+		return s.IceBreakers[i].Locale < s.IceBreakers[j].Locale
+	})
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[SettingsField]json.RawMessage)
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,86 @@
+package messenger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDiffSettings(t *testing.T) {
+	current := Settings{}
+	current.AddGreeting("hu_HU", "Szia!")
+	current.AddGreeting(DefaultLocale, "Hello!")
+	current.SetGetStarted("start")
+	current.AddPersistentMenu(DefaultLocale, false)
+
+	desired := Settings{}
+	desired.AddGreeting(DefaultLocale, "Hello!")
+	desired.AddGreeting("hu_HU", "Szia!")
+	desired.SetGetStarted("get_started")
+	url := "https://example.com/link"
+	desired.AccountLinkingURL = &url
+
+	plan, err := DiffSettings(current, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `+ account_linking_url: "https://example.com/link"
+~ get_started: {"payload":"start"} => {"payload":"get_started"}
+- persistent_menu: [{"locale":"default","composer_input_disabled":false,"call_to_actions":null}]
+`
+	if plan.String() != expected {
+		t.Errorf("unexpected plan:\n%s", plan)
+	}
+
+	plan, err = DiffSettings(desired, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 0 {
+		t.Errorf("expected no changes, got:\n%s", plan)
+	}
+}
+
+func TestSyncPageSettings(t *testing.T) {
+	var requests []string
+	c := newTestController(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"data":[{"get_started":{"payload":"start"},"greeting":[{"locale":"default","text":"Hello"}]}]}`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+string(body))
+		w.Write([]byte(`{"result":"success"}`))
+	})
+
+	desired := Settings{}
+	desired.SetGetStarted("start")
+	desired.AddGreeting(DefaultLocale, "Hello!")
+
+	out := &bytes.Buffer{}
+	plan, err := SyncPageSettings(c, "token", desired, true, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || len(requests) != 0 {
+		t.Errorf("dry run sent requests or planned unexpected changes: %v %v", plan, requests)
+	}
+	if out.String() != `~ greeting: [{"locale":"default","text":"Hello"}] => [{"locale":"default","text":"Hello!"}]`+"\n" {
+		t.Errorf("unexpected dry run output: %s", out)
+	}
+
+	desired.GetStarted = nil
+	if _, err := SyncPageSettings(c, "token", desired, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`POST {"greeting":[{"locale":"default","text":"Hello!"}]}`,
+		`DELETE {"fields":["get_started"]}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+}
@@ -0,0 +1,191 @@
+package messenger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWhitelistedDomains(t *testing.T) {
+	s := Settings{}
+	if err := s.AddWhitelistedDomain("https://example.com"); err != nil {
+		t.Error(err)
+	}
+	for _, domain := range []string{"http://example.com", "example.com", "https://", "://x"} {
+		if err := s.AddWhitelistedDomain(domain); err == nil {
+			t.Errorf("invalid domain %q is accepted", domain)
+		}
+	}
+	if len(s.WhitelistedDomains) != 1 {
+		t.Errorf("expected 1 whitelisted domain, got %v", s.WhitelistedDomains)
+	}
+
+	for i := len(s.WhitelistedDomains); i < WhitelistedDomainsLimit; i++ {
+		s.AddWhitelistedDomain("https://example.com")
+	}
+	if err := s.AddWhitelistedDomain("https://example.com"); err == nil {
+		t.Error("whitelisted domain limit is not checked")
+	}
+	if err := ValidateWhitelistedDomains(s.WhitelistedDomains); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIceBreakers(t *testing.T) {
+	s := Settings{}
+	for i := 0; i < IceBreakersLimit; i++ {
+		if err := s.AddIceBreaker(DefaultLocale, "Where are you?", "where"); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := s.AddIceBreaker(DefaultLocale, "One more?", "more"); err == nil {
+		t.Error("ice breaker limit is not checked")
+	}
+	if err := s.AddIceBreaker("hu_HU", "Hol vagytok?", "where"); err != nil {
+		t.Error(err)
+	}
+	if len(s.IceBreakers) != 2 || len(s.IceBreakers[0].CTAs) != IceBreakersLimit || len(s.IceBreakers[1].CTAs) != 1 {
+		t.Errorf("unexpected ice breakers: %+v", s.IceBreakers)
+	}
+	for _, i := range s.IceBreakers {
+		if err := i.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := (IceBreakers{Locale: DefaultLocale, CTAs: []IceBreaker{{Question: "Hi"}}}).Validate(); err == nil {
+		t.Error("ice breaker without payload is accepted")
+	}
+}
+
+func TestIceBreakerListUnmarshal(t *testing.T) {
+	var s Settings
+	err := json.Unmarshal([]byte(`{"ice_breakers":[{"question":"Where are you?","payload":"where"},{"question":"Open?","payload":"open"}]}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.IceBreakers) != 1 || s.IceBreakers[0].Locale != DefaultLocale || len(s.IceBreakers[0].CTAs) != 2 {
+		t.Errorf("legacy ice breakers are not decoded: %+v", s.IceBreakers)
+	}
+
+	err = json.Unmarshal([]byte(`{"ice_breakers":[{"locale":"hu_HU","call_to_actions":[{"question":"Hol vagytok?","payload":"where"}]}]}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.IceBreakers) != 1 || s.IceBreakers[0].Locale != "hu_HU" || s.IceBreakers[0].CTAs[0].Question != "Hol vagytok?" {
+		t.Errorf("localized ice breakers are not decoded: %+v", s.IceBreakers)
+	}
+}
+
+func TestHomeURLAndPaymentSettings(t *testing.T) {
+	s := Settings{}
+	s.SetHomeURL("https://example.com/home", true)
+	if err := s.HomeURL.Validate(); err != nil {
+		t.Error(err)
+	}
+	s.SetHomeURL("http://example.com/home", true)
+	if err := s.HomeURL.Validate(); err == nil {
+		t.Error("http home url is accepted")
+	}
+
+	s.SetPaymentSettings(PaymentSettings{PrivacyURL: "https://example.com/privacy", TestUsers: []string{"123"}})
+	if err := s.PaymentSettings.Validate(); err != nil {
+		t.Error(err)
+	}
+	s.PaymentSettings.PrivacyURL = "ftp://example.com"
+	if err := s.PaymentSettings.Validate(); err == nil {
+		t.Error("non https privacy url is accepted")
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestPersistentMenuValidate(t *testing.T) {
+	menu := PersistentMenu{Locale: DefaultLocale}
+	menu.AddCTA(CTA{Title: "Shop", Type: CTATypeURL, URL: strPtr("https://example.com"), WebviewHeightRatio: strPtr(WebviewHeightRatioFull)})
+	menu.AddCTA(CTA{Title: "Help", Type: CTATypePostback, Payload: strPtr("help")})
+	menu.AddCTA(CTA{Title: "More", Type: CTATypeNested, CTAs: []CTA{
+		{Title: "Even more", Type: CTATypeNested, CTAs: []CTA{
+			{Title: "Contact", Type: CTATypePostback, Payload: strPtr("contact")},
+		}},
+	}})
+	if err := menu.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		name string
+		ctas []CTA
+		path string
+	}{
+		{"lvl1 count", make([]CTA, 4), "call_to_actions"},
+		{"no title", []CTA{{Type: CTATypePostback, Payload: strPtr("p")}}, "call_to_actions[0].title"},
+		{"long title", []CTA{{Title: strings.Repeat("é", PersistentMenuTitleLimit+1), Type: CTATypePostback, Payload: strPtr("p")}}, "call_to_actions[0].title"},
+		{"postback without payload", []CTA{{Title: "a", Type: CTATypePostback}}, "call_to_actions[0].payload"},
+		{"url without url", []CTA{{Title: "a", Type: CTATypeURL}}, "call_to_actions[0].url"},
+		{"url with payload", []CTA{{Title: "a", Type: CTATypeURL, URL: strPtr("https://example.com"), Payload: strPtr("p")}}, "call_to_actions[0].payload"},
+		{"invalid webview height ratio", []CTA{{Title: "a", Type: CTATypeURL, URL: strPtr("https://example.com"), WebviewHeightRatio: strPtr("huge")}}, "call_to_actions[0].webview_height_ratio"},
+		{"empty nested", []CTA{{Title: "a", Type: CTATypeNested}}, "call_to_actions[0].call_to_actions"},
+		{"unknown type", []CTA{{Title: "a", Type: "phone"}}, "call_to_actions[0].type"},
+		{"lvl2 count", []CTA{{Title: "a", Type: CTATypeNested, CTAs: make([]CTA, 4)}}, "call_to_actions[0].call_to_actions"},
+		{"depth", []CTA{{Title: "a", Type: CTATypeNested, CTAs: []CTA{{Title: "b", Type: CTATypeNested, CTAs: []CTA{{Title: "c", Type: CTATypeNested, CTAs: []CTA{{Title: "d", Type: CTATypePostback, Payload: strPtr("d")}}}}}}}}, "call_to_actions[0].call_to_actions[0].call_to_actions[0].call_to_actions"},
+	}
+	for _, test := range tests {
+		m := PersistentMenu{Locale: DefaultLocale, CTAs: test.ctas}
+		if !hasValidationError(m.Validate(), test.path) {
+			t.Errorf("%s: expected error at %s, got %v", test.name, test.path, m.Validate())
+		}
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	s := Settings{}
+	s.SetGetStarted("start")
+	s.AddGreeting(DefaultLocale, "Hello!")
+	s.AddGreeting("hu_HU", "Szia!")
+	menu := s.AddPersistentMenu(DefaultLocale, false)
+	menu.AddCTA(CTA{Title: "Help", Type: CTATypePostback, Payload: strPtr("help")})
+	s.AddWhitelistedDomain("https://example.com")
+	s.AddIceBreaker(DefaultLocale, "Where are you?", "where")
+	s.SetHomeURL("https://example.com/home", false)
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.GetStarted = nil
+	s.AddGreeting("hu_HU", strings.Repeat("ű", GreetingTextLengthLimit+1))
+	s.Greetings[0].Locale = "en_US"
+	s.IceBreakers[0].Locale = "en_US"
+	s.WhitelistedDomains = []string{"http://example.com"}
+	s.SetHomeURL("https://other.example.com/home", false)
+	err := s.Validate()
+
+	for _, path := range []string{
+		"persistent_menu",
+		"greeting[2].text",
+		"greeting[2].locale",
+		"greeting",
+		"ice_breakers",
+		"whitelisted_domains[0]",
+		"home_url.url",
+	} {
+		if !hasValidationError(err, path) {
+			t.Errorf("expected error at %s, got %v", path, err)
+		}
+	}
+}
+
+func hasValidationError(err error, path string) bool {
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		return false
+	}
+	for _, e := range errs {
+		if e.Path == path {
+			return true
+		}
+	}
+	return false
+}
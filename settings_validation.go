@@ -0,0 +1,350 @@
+package messenger
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Settings validation constants
+const (
+	PersistentMenuDepthLimit  = 3
+	GetStartedPayloadLimit    = 1000
+	CTAPayloadLimit           = 1000
+	WebviewHeightRatioCompact = "compact"
+	WebviewHeightRatioTall    = "tall"
+	WebviewHeightRatioFull    = "full"
+)
+
+// ValidationError describes an invalid settings property
+type ValidationError struct {
+	// Path locates the property in the JSON form of the settings, e.g. persistent_menu[0].call_to_actions[1].title
+	Path    string
+	Message string
+}
+
+// Error ...
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors collects all problems found by a validation
+type ValidationErrors []ValidationError
+
+// Error ...
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationErrors) add(path, format string, args ...interface{}) {
+	*e = append(*e, ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// err returns nil for an empty list, so the result can be returned as error
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate validates every property of the settings:
+// the limits and the required fields of each property, the menu depth and the fields required by the CTA types,
+// unsupported and duplicate locales, the presence of the default locale in greetings, persistent menus and ice breakers,
+// and that a persistent menu requires the get started button.
+// The returned error is ValidationErrors listing all problems.
+func (s Settings) Validate() error {
+	var errs ValidationErrors
+
+	if s.AccountLinkingURL != nil {
+		validateHTTPSURL(SettingsFieldAccountLinkingURL.String(), *s.AccountLinkingURL, &errs)
+	}
+
+	if s.GetStarted != nil {
+		path := SettingsFieldGetStarted.String() + ".payload"
+		if s.GetStarted.Payload == "" {
+			errs.add(path, "is required")
+		} else if ExceedsLimit(s.GetStarted.Payload, GetStartedPayloadLimit) {
+			errs.add(path, "exceeds the %d character limit", GetStartedPayloadLimit)
+		}
+	}
+
+	greetingLocales := make([]string, len(s.Greetings))
+	for i, g := range s.Greetings {
+		g.validate(fmt.Sprintf("%s[%d]", SettingsFieldGreeting, i), &errs)
+		greetingLocales[i] = g.Locale
+	}
+	validateLocales(SettingsFieldGreeting.String(), greetingLocales, &errs)
+
+	menuLocales := make([]string, len(s.PersistentMenu))
+	for i, m := range s.PersistentMenu {
+		m.validate(fmt.Sprintf("%s[%d]", SettingsFieldPersistentMenu, i), &errs)
+		menuLocales[i] = m.Locale
+	}
+	validateLocales(SettingsFieldPersistentMenu.String(), menuLocales, &errs)
+	if len(s.PersistentMenu) > 0 && s.GetStarted == nil {
+		errs.add(SettingsFieldPersistentMenu.String(), "requires get_started to be set")
+	}
+
+	validateWhitelistedDomains(SettingsFieldWhitelistedDomains.String(), s.WhitelistedDomains, &errs)
+
+	iceBreakerLocales := make([]string, len(s.IceBreakers))
+	for i, ib := range s.IceBreakers {
+		ib.validate(fmt.Sprintf("%s[%d]", SettingsFieldIceBreakers, i), &errs)
+		iceBreakerLocales[i] = ib.Locale
+	}
+	validateLocales(SettingsFieldIceBreakers.String(), iceBreakerLocales, &errs)
+
+	if s.HomeURL != nil {
+		s.HomeURL.validate(SettingsFieldHomeURL.String(), &errs)
+		if !isWhitelisted(s.HomeURL.URL, s.WhitelistedDomains) {
+			errs.add(SettingsFieldHomeURL.String()+".url", "domain has to be whitelisted")
+		}
+	}
+
+	if s.PaymentSettings != nil {
+		s.PaymentSettings.validate(SettingsFieldPaymentSettings.String(), &errs)
+	}
+
+	return errs.err()
+}
+
+// Validate validates the persistent menu
+// Max menu elem: 3 element / level
+// Max depth: 3 level
+// The title and the fields required by the type of each CTA are checked as well.
+func (p *PersistentMenu) Validate() error {
+	var errs ValidationErrors
+	p.validate("", &errs)
+	return errs.err()
+}
+
+func (p PersistentMenu) validate(path string, errs *ValidationErrors) {
+	validateCTAs(joinPath(path, "call_to_actions"), p.CTAs, 1, errs)
+}
+
+func validateCTAs(path string, ctas []CTA, level int, errs *ValidationErrors) {
+	if len(ctas) > PersistentMenuButtonLimit {
+		errs.add(path, "CTA limit of %d exceeded in lvl%d", PersistentMenuButtonLimit, level)
+	}
+
+	for i, cta := range ctas {
+		cta.validate(fmt.Sprintf("%s[%d]", path, i), level, errs)
+	}
+}
+
+func (c CTA) validate(path string, level int, errs *ValidationErrors) {
+	if c.Title == "" {
+		errs.add(path+".title", "is required")
+	} else if ExceedsLimit(c.Title, PersistentMenuTitleLimit) {
+		errs.add(path+".title", "exceeds the %d character limit", PersistentMenuTitleLimit)
+	}
+
+	switch c.Type {
+	case CTATypePostback:
+		if c.Payload == nil || *c.Payload == "" {
+			errs.add(path+".payload", "is required for %s type", c.Type)
+		} else if ExceedsLimit(*c.Payload, CTAPayloadLimit) {
+			errs.add(path+".payload", "exceeds the %d character limit", CTAPayloadLimit)
+		}
+		if c.URL != nil {
+			errs.add(path+".url", "is not allowed for %s type", c.Type)
+		}
+	case CTATypeURL:
+		if c.URL == nil || *c.URL == "" {
+			errs.add(path+".url", "is required for %s type", c.Type)
+		} else if u, err := url.Parse(*c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add(path+".url", "is not a valid http(s) url: %s", *c.URL)
+		}
+		if c.Payload != nil {
+			errs.add(path+".payload", "is not allowed for %s type", c.Type)
+		}
+		if c.WebviewHeightRatio != nil {
+			switch *c.WebviewHeightRatio {
+			case WebviewHeightRatioCompact, WebviewHeightRatioTall, WebviewHeightRatioFull:
+			default:
+				errs.add(path+".webview_height_ratio", "has to be compact, tall or full")
+			}
+		}
+	case CTATypeNested:
+		if len(c.CTAs) == 0 {
+			errs.add(path+".call_to_actions", "is required for %s type", c.Type)
+		}
+		if c.URL != nil || c.Payload != nil {
+			errs.add(path, "url and payload are not allowed for %s type", c.Type)
+		}
+	default:
+		errs.add(path+".type", "unknown type: %q", c.Type)
+	}
+
+	if c.Type != CTATypeNested && len(c.CTAs) > 0 {
+		errs.add(path+".call_to_actions", "is allowed only for %s type", CTATypeNested)
+	}
+
+	if len(c.CTAs) > 0 {
+		if level >= PersistentMenuDepthLimit {
+			errs.add(path+".call_to_actions", "maximum menu depth is %d lvl", PersistentMenuDepthLimit)
+			return
+		}
+		validateCTAs(path+".call_to_actions", c.CTAs, level+1, errs)
+	}
+}
+
+// Validate validates the greeting text length
+func (g Greeting) Validate() error {
+	var errs ValidationErrors
+	g.validate("", &errs)
+	return errs.err()
+}
+
+func (g Greeting) validate(path string, errs *ValidationErrors) {
+	if g.Text == "" {
+		errs.add(joinPath(path, "text"), "is required")
+	} else if ExceedsLimit(g.Text, GreetingTextLengthLimit) {
+		errs.add(joinPath(path, "text"), "exceeds the %d character limit", GreetingTextLengthLimit)
+	}
+}
+
+// ValidateWhitelistedDomain checks that the domain is an absolute https url
+func ValidateWhitelistedDomain(domain string) error {
+	var errs ValidationErrors
+	validateHTTPSURL("", domain, &errs)
+	return errs.err()
+}
+
+// ValidateWhitelistedDomains validates the whitelisted domains and their count
+func ValidateWhitelistedDomains(domains []string) error {
+	var errs ValidationErrors
+	validateWhitelistedDomains("", domains, &errs)
+	return errs.err()
+}
+
+func validateWhitelistedDomains(path string, domains []string, errs *ValidationErrors) {
+	if len(domains) > WhitelistedDomainsLimit {
+		errs.add(path, "limit of %d domains exceeded", WhitelistedDomainsLimit)
+	}
+	for i, d := range domains {
+		validateHTTPSURL(fmt.Sprintf("%s[%d]", path, i), d, errs)
+	}
+}
+
+// Validate validates the count and the questions of the ice breakers
+func (i IceBreakers) Validate() error {
+	var errs ValidationErrors
+	i.validate("", &errs)
+	return errs.err()
+}
+
+func (i IceBreakers) validate(path string, errs *ValidationErrors) {
+	ctasPath := joinPath(path, "call_to_actions")
+	if len(i.CTAs) == 0 {
+		errs.add(ctasPath, "is required")
+	}
+	if len(i.CTAs) > IceBreakersLimit {
+		errs.add(ctasPath, "limit of %d ice breakers exceeded", IceBreakersLimit)
+	}
+	for n, cta := range i.CTAs {
+		ctaPath := fmt.Sprintf("%s[%d]", ctasPath, n)
+		if cta.Question == "" {
+			errs.add(ctaPath+".question", "is required")
+		} else if ExceedsLimit(cta.Question, IceBreakerQuestionLimit) {
+			errs.add(ctaPath+".question", "exceeds the %d character limit", IceBreakerQuestionLimit)
+		}
+		if cta.Payload == "" {
+			errs.add(ctaPath+".payload", "is required")
+		}
+	}
+}
+
+// Validate validates the home url, it has to be https and its webview height ratio has to be tall
+func (h HomeURL) Validate() error {
+	var errs ValidationErrors
+	h.validate("", &errs)
+	return errs.err()
+}
+
+func (h HomeURL) validate(path string, errs *ValidationErrors) {
+	validateHTTPSURL(joinPath(path, "url"), h.URL, errs)
+	if h.WebviewHeightRatio != HomeURLWebviewHeightRatio {
+		errs.add(joinPath(path, "webview_height_ratio"), "has to be tall")
+	}
+}
+
+// Validate validates the payment settings, the privacy url has to be https
+func (p PaymentSettings) Validate() error {
+	var errs ValidationErrors
+	p.validate("", &errs)
+	return errs.err()
+}
+
+func (p PaymentSettings) validate(path string, errs *ValidationErrors) {
+	if p.PrivacyURL != "" {
+		validateHTTPSURL(joinPath(path, "privacy_url"), p.PrivacyURL, errs)
+	}
+}
+
+// validateLocales checks that the locales are supported, unique and the default locale is present
+func validateLocales(path string, locales []string, errs *ValidationErrors) {
+	if len(locales) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i, locale := range locales {
+		localePath := fmt.Sprintf("%s[%d].locale", path, i)
+		if locale == "" {
+			errs.add(localePath, "is required")
+			continue
+		}
+		if !IsSupportedLocale(locale) {
+			errs.add(localePath, "unsupported locale: %s", locale)
+		}
+		if seen[locale] {
+			errs.add(localePath, "duplicate locale: %s", locale)
+		}
+		seen[locale] = true
+	}
+
+	if !seen[DefaultLocale] {
+		errs.add(path, "%q locale is missing", DefaultLocale)
+	}
+}
+
+func validateHTTPSURL(path, rawURL string, errs *ValidationErrors) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		errs.add(path, "has to be an https url: %s", rawURL)
+	}
+}
+
+// isWhitelisted reports whether the url belongs to one of the whitelisted domains
+func isWhitelisted(rawURL string, domains []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, d := range domains {
+		w, err := url.Parse(d)
+		if err == nil && strings.EqualFold(w.Host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
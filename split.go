@@ -0,0 +1,126 @@
+package messenger
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// SplitText splits the text into ordered parts of at most limit characters.
+// Breaks are made preferably between paragraphs, then between sentences, then between words;
+// a part is cut in the middle of a word only if it has no whitespace at all,
+// but never inside a URL or a character (grapheme cluster).
+// Whitespace around the breaks is removed.
+func SplitText(text string, limit int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" || limit <= 0 {
+		return nil
+	}
+
+	var parts []string
+	for text != "" {
+		brk := splitPosition(text, limit)
+		if part := strings.TrimRightFunc(text[:brk], unicode.IsSpace); part != "" {
+			parts = append(parts, part)
+		}
+		text = strings.TrimLeftFunc(text[brk:], unicode.IsSpace)
+	}
+	return parts
+}
+
+// splitPosition returns the byte offset where the first part of the text should end.
+func splitPosition(text string, limit int) int {
+	end := 0
+	for i := 0; i < limit && end < len(text); i++ {
+		end += characterLength(text[end:])
+	}
+	if end == len(text) {
+		return end
+	}
+
+	window := text[:end]
+	// breaks in the first half of the window would produce too short parts
+	half := len(window) / 2
+
+	if i := strings.LastIndex(window, "\n\n"); i > half {
+		return i
+	}
+	if i := lastSentenceEnd(window, text[end:]); i > half {
+		return i
+	}
+	if i := strings.LastIndexFunc(window, unicode.IsSpace); i > 0 {
+		return i
+	}
+
+	for _, span := range urlPattern.FindAllStringIndex(text, -1) {
+		if span[0] > 0 && span[0] < end && end < span[1] {
+			return span[0]
+		}
+	}
+	return end
+}
+
+// lastSentenceEnd returns the byte offset after the last sentence terminator of the window
+// which is followed by whitespace, or -1 if there's none.
+func lastSentenceEnd(window, rest string) int {
+	for i := len(window); i > 0; {
+		r, size := utf8.DecodeLastRuneInString(window[:i])
+		i -= size
+		if !strings.ContainsRune(".!?…", r) {
+			continue
+		}
+
+		after := window[i+size:]
+		if after == "" {
+			after = rest
+		}
+		next, _ := utf8.DecodeRuneInString(after)
+		if unicode.IsSpace(next) {
+			return i + size
+		}
+	}
+	return -1
+}
+
+// Split splits the message into ordered messages complying with the text length limit.
+// Quick replies and metadata are attached to the last message only.
+// Messages with an attachment, with a text within the limit or with a blank text are returned unchanged.
+func (m SendMessage) Split() []SendMessage {
+	if m.Attachment != nil || !ExceedsLimit(m.Text, SendMessageTextLengthLimit) {
+		return []SendMessage{m}
+	}
+
+	parts := SplitText(m.Text, SendMessageTextLengthLimit)
+	if len(parts) == 0 {
+		return []SendMessage{m}
+	}
+	messages := make([]SendMessage, len(parts))
+	for i, part := range parts {
+		messages[i].Text = part
+	}
+
+	last := &messages[len(messages)-1]
+	last.QuickReplies = m.QuickReplies
+	last.Metadata = m.Metadata
+
+	return messages
+}
+
+// Split splits the query into ordered queries to the same recipient whose messages comply with the text length limit.
+// See SendMessage.Split for details.
+func (q MessageQuery) Split() []MessageQuery {
+	if q.Message == nil {
+		return []MessageQuery{q}
+	}
+
+	messages := q.Message.Split()
+	queries := make([]MessageQuery, len(messages))
+	for i := range messages {
+		queries[i] = q
+		queries[i].Message = &messages[i]
+	}
+	return queries
+}
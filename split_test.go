@@ -0,0 +1,125 @@
+package messenger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitTextShort(t *testing.T) {
+	parts := SplitText("  Szia! 👋  ", 10)
+	if len(parts) != 1 || parts[0] != "Szia! 👋" {
+		t.Errorf("unexpected parts: %q", parts)
+	}
+	if parts := SplitText(" \n ", 10); parts != nil {
+		t.Errorf("expected no parts for blank text, got %q", parts)
+	}
+}
+
+func TestSplitTextBoundaries(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "paragraph",
+			text:  "First paragraph. Still first.\n\nSecond paragraph.",
+			limit: 40,
+			want:  []string{"First paragraph. Still first.", "Second paragraph."},
+		},
+		{
+			name:  "sentence",
+			text:  "Első mondat. Második mondat! Harmadik mondat?",
+			limit: 30,
+			want:  []string{"Első mondat. Második mondat!", "Harmadik mondat?"},
+		},
+		{
+			name:  "word",
+			text:  "lorem ipsum dolor sit amet consectetur",
+			limit: 15,
+			want:  []string{"lorem ipsum", "dolor sit amet", "consectetur"},
+		},
+		{
+			name:  "url",
+			text:  "check this link https://example.com/p please",
+			limit: 25,
+			want:  []string{"check this link", "https://example.com/p", "please"},
+		},
+		{
+			name:  "url without whitespace",
+			text:  "ok:https://x.io",
+			limit: 12,
+			want:  []string{"ok:", "https://x.io"},
+		},
+		{
+			name:  "graphemes",
+			text:  strings.Repeat("👨‍👩‍👧‍👦", 5),
+			limit: 2,
+			want:  []string{"👨‍👩‍👧‍👦👨‍👩‍👧‍👦", "👨‍👩‍👧‍👦👨‍👩‍👧‍👦", "👨‍👩‍👧‍👦"},
+		},
+	}
+
+	for _, test := range tests {
+		parts := SplitText(test.text, test.limit)
+		if strings.Join(parts, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%s: got %q, want %q", test.name, parts, test.want)
+		}
+	}
+}
+
+func TestSplitTextLimit(t *testing.T) {
+	text := strings.Repeat("Árvíztűrő tükörfúrógép, 🇭🇺 zászló. ", 200)
+	parts := SplitText(text, SendMessageTextLengthLimit)
+	if len(parts) < 2 {
+		t.Fatalf("expected multiple parts, got %d", len(parts))
+	}
+	for i, part := range parts {
+		if CharacterCount(part) > SendMessageTextLengthLimit {
+			t.Errorf("part %d exceeds the limit", i)
+		}
+		if i < len(parts)-1 && !strings.HasSuffix(part, ".") {
+			t.Errorf("part %d is not split at a sentence boundary: %q", i, part[len(part)-20:])
+		}
+	}
+}
+
+func TestMessageQuerySplit(t *testing.T) {
+	q := MessageQuery{
+		Recipient:     Recipient{ID: "123"},
+		MessagingType: MessagingTypeResponse,
+		PersonaID:     "456",
+		Message: &SendMessage{
+			Text:         strings.Repeat("word ", 1000),
+			QuickReplies: []QuickReply{{ContentType: ContentTypeText, Title: "OK", Payload: "ok"}},
+			Metadata:     "meta",
+		},
+	}
+
+	queries := q.Split()
+	if len(queries) != 3 {
+		t.Fatalf("expected 3 queries, got %d", len(queries))
+	}
+	for i, query := range queries {
+		if query.Recipient.ID != "123" || query.MessagingType != MessagingTypeResponse || query.PersonaID != "456" {
+			t.Errorf("query %d lost its options", i)
+		}
+		if err := query.Message.Validate(); err != nil {
+			t.Errorf("query %d: %v", i, err)
+		}
+		last := i == len(queries)-1
+		if (len(query.Message.QuickReplies) > 0) != last || (query.Message.Metadata != "") != last {
+			t.Errorf("query %d: quick replies and metadata must be attached to the last part only", i)
+		}
+	}
+
+	short := MessageQuery{Message: &SendMessage{Text: "short"}}
+	if queries := short.Split(); len(queries) != 1 || queries[0].Message.Text != "short" {
+		t.Error("short message is modified")
+	}
+
+	blank := SendMessage{Text: strings.Repeat(" ", SendMessageTextLengthLimit+1), Metadata: "meta"}
+	if messages := blank.Split(); len(messages) != 1 || messages[0].Metadata != "meta" {
+		t.Error("blank message is modified")
+	}
+}
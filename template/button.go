@@ -1,6 +1,10 @@
 package template
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	messenger "github.com/hellowearemito/go-messenger-structs"
+)
 
 const TemplateTypeButton TemplateType = "button"
 
@@ -23,6 +27,14 @@ func (b ButtonTemplate) Validate() error {
 	if len(b.Buttons) > ButtonTemplateButtonsLimit {
 		return ErrButtonsLimitExceeded
 	}
+	if messenger.ExceedsLimit(b.Text, ButtonTemplateTextLengthLimit) {
+		return ErrTextLengthExceeded
+	}
+	for _, button := range b.Buttons {
+		if messenger.ExceedsLimit(button.Title, GenericTemplateCallToActionTitleLimit) {
+			return ErrCallToActionTitleLengthExceeded
+		}
+	}
 	return nil
 }
 
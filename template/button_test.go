@@ -1,6 +1,9 @@
 package template
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestButtonType(t *testing.T) {
 	template := &ButtonTemplate{}
@@ -25,3 +28,15 @@ func TestButtonType(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestButtonTemplateButtonTitle(t *testing.T) {
+	template := &ButtonTemplate{}
+	template.AddButton(Button{Title: strings.Repeat("👍🏾", GenericTemplateCallToActionTitleLimit)})
+	if err := template.Validate(); err != nil {
+		t.Error(err)
+	}
+	template.AddButton(Button{Title: strings.Repeat("👍🏾", GenericTemplateCallToActionTitleLimit+1)})
+	if err := template.Validate(); err != ErrCallToActionTitleLengthExceeded {
+		t.Error(err)
+	}
+}
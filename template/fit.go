@@ -0,0 +1,96 @@
+package template
+
+import (
+	"fmt"
+
+	messenger "github.com/hellowearemito/go-messenger-structs"
+)
+
+// Fit returns the template adapted to the Messenger limits instead of failing validation:
+// titles and subtitles are truncated with an ellipsis, the excess buttons are dropped
+// and more than 10 bubbles are split into multiple templates, one per message.
+func (g GenericTemplate) Fit() ([]GenericTemplate, messenger.FitReport) {
+	var report messenger.FitReport
+
+	elements := make([]Element, len(g.Elements))
+	for i, elem := range g.Elements {
+		elements[i] = fitElement(elem, fmt.Sprintf("elements[%d]", i), GenericTemplateTitleLengthLimit, GenericTemplateSubtitleLengthLimit, GenericTemplateCallToActionItemsLimit, &report)
+	}
+
+	if len(elements) <= GenericTemplateBubblesPerMessageLimit {
+		g.Elements = elements
+		return []GenericTemplate{g}, report
+	}
+
+	var templates []GenericTemplate
+	for len(elements) > 0 {
+		n := GenericTemplateBubblesPerMessageLimit
+		if len(elements) < n {
+			n = len(elements)
+		}
+		t := g
+		t.Elements = elements[:n:n]
+		templates = append(templates, t)
+		elements = elements[n:]
+	}
+	report.Add("elements", messenger.FitActionSplit, fmt.Sprintf("%d elements split into %d messages", len(g.Elements), len(templates)))
+
+	return templates, report
+}
+
+// Fit returns the template adapted to the Messenger limits instead of failing validation:
+// the text and the button titles are truncated with an ellipsis and the excess buttons are dropped.
+func (b ButtonTemplate) Fit() (ButtonTemplate, messenger.FitReport) {
+	var report messenger.FitReport
+
+	b.Text = messenger.FitText(b.Text, ButtonTemplateTextLengthLimit, "text", &report)
+	b.Buttons = fitButtons(b.Buttons, ButtonTemplateButtonsLimit, "buttons", &report)
+
+	return b, report
+}
+
+// Fit returns the template adapted to the Messenger limits instead of failing validation:
+// titles and subtitles are truncated with an ellipsis, the excess buttons and elements are dropped.
+func (l ListTemplate) Fit() (ListTemplate, messenger.FitReport) {
+	var report messenger.FitReport
+
+	var elements []Element
+	for i, elem := range l.Elements {
+		path := fmt.Sprintf("elements[%d]", i)
+		if i >= ListTemplateElementsLimit {
+			report.Add(path, messenger.FitActionDropped, elem.Title)
+			continue
+		}
+		elements = append(elements, fitElement(elem, path, ListTemplateTitleLengthLimit, ListTemplateSubtitleLengthLimit, ListTemplateButtonsLimit, &report))
+	}
+	l.Elements = elements
+	l.Buttons = fitButtons(l.Buttons, ListTemplateButtonsLimit, "buttons", &report)
+
+	return l, report
+}
+
+// fitElement returns a copy of the element complying with the given limits.
+func fitElement(e Element, path string, titleLimit, subtitleLimit, buttonsLimit int, report *messenger.FitReport) Element {
+	e.Title = messenger.FitText(e.Title, titleLimit, path+".title", report)
+	e.Subtitle = messenger.FitText(e.Subtitle, subtitleLimit, path+".subtitle", report)
+	e.Buttons = fitButtons(e.Buttons, buttonsLimit, path+".buttons", report)
+	return e
+}
+
+// fitButtons returns a copy of the buttons with truncated titles, without the buttons above the limit.
+func fitButtons(buttons []Button, limit int, path string, report *messenger.FitReport) []Button {
+	if buttons == nil {
+		return nil
+	}
+
+	fitted := make([]Button, 0, len(buttons))
+	for i, b := range buttons {
+		if i >= limit {
+			report.Add(fmt.Sprintf("%s[%d]", path, i), messenger.FitActionDropped, b.Title)
+			continue
+		}
+		b.Title = messenger.FitText(b.Title, GenericTemplateCallToActionTitleLimit, fmt.Sprintf("%s[%d].title", path, i), report)
+		fitted = append(fitted, b)
+	}
+	return fitted
+}
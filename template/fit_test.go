@@ -0,0 +1,115 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	messenger "github.com/hellowearemito/go-messenger-structs"
+)
+
+func TestGenericFit(t *testing.T) {
+	template := GenericTemplate{TemplateBase: TemplateBase{Type: TemplateTypeGeneric}}
+	for i := 0; i < 23; i++ {
+		template.AddElement(Element{Title: "Ajánlat"})
+	}
+	template.Elements[0].Title = strings.Repeat("Árvíztűrő ", 6)
+	template.Elements[0].Subtitle = strings.Repeat("🇭🇺", 100)
+	template.Elements[0].AddButton(
+		NewPostbackButton("Részletek megtekintése", "details"),
+		NewPostbackButton("b", "b"),
+		NewPostbackButton("c", "c"),
+		NewPostbackButton("d", "d"),
+	)
+
+	templates, report := template.Fit()
+	if len(templates) != 3 {
+		t.Fatalf("expected 3 templates, got %d", len(templates))
+	}
+	if len(templates[0].Elements) != 10 || len(templates[1].Elements) != 10 || len(templates[2].Elements) != 3 {
+		t.Error("elements are not split into 10-10-3 bubbles")
+	}
+	for _, fitted := range templates {
+		if fitted.TemplateBase.Type != TemplateTypeGeneric {
+			t.Error("template base is not kept")
+		}
+		if err := fitted.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	first := templates[0].Elements[0]
+	if messenger.CharacterCount(first.Title) > GenericTemplateTitleLengthLimit || !strings.HasSuffix(first.Title, messenger.Ellipsis) {
+		t.Errorf("title is not truncated: %q", first.Title)
+	}
+	if first.Subtitle != strings.Repeat("🇭🇺", GenericTemplateSubtitleLengthLimit-1)+messenger.Ellipsis {
+		t.Errorf("subtitle is not truncated at character boundaries: %q", first.Subtitle)
+	}
+	if len(first.Buttons) != GenericTemplateCallToActionItemsLimit {
+		t.Errorf("expected %d buttons, got %d", GenericTemplateCallToActionItemsLimit, len(first.Buttons))
+	}
+
+	if len(template.Elements) != 23 || len(template.Elements[0].Buttons) != 4 || template.Elements[0].Buttons[0].Title != "Részletek megtekintése" {
+		t.Error("original template is modified")
+	}
+
+	expected := []messenger.FitChange{
+		{Path: "elements[0].title", Action: messenger.FitActionTruncated, Detail: template.Elements[0].Title},
+		{Path: "elements[0].subtitle", Action: messenger.FitActionTruncated, Detail: template.Elements[0].Subtitle},
+		{Path: "elements[0].buttons[0].title", Action: messenger.FitActionTruncated, Detail: "Részletek megtekintése"},
+		{Path: "elements[0].buttons[3]", Action: messenger.FitActionDropped, Detail: "d"},
+		{Path: "elements", Action: messenger.FitActionSplit, Detail: "23 elements split into 3 messages"},
+	}
+	if len(report) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), report)
+	}
+	for i := range expected {
+		if report[i] != expected[i] {
+			t.Errorf("change %d: expected %v, got %v", i, expected[i], report[i])
+		}
+	}
+}
+
+func TestGenericFitUnchanged(t *testing.T) {
+	template := GenericTemplate{}
+	template.AddElement(Element{Title: "こんにちは", Buttons: []Button{NewPostbackButton("OK", "ok")}})
+
+	templates, report := template.Fit()
+	if report.Changed() {
+		t.Errorf("valid template reported as changed: %v", report)
+	}
+	if len(templates) != 1 || templates[0].Elements[0].Title != "こんにちは" {
+		t.Error("valid template is modified")
+	}
+}
+
+func TestButtonFit(t *testing.T) {
+	template := ButtonTemplate{Text: strings.Repeat("ő", ButtonTemplateTextLengthLimit+1)}
+	template.AddButton(NewPostbackButton("a", "a"), NewPostbackButton("b", "b"), NewPostbackButton("c", "c"), NewPostbackButton("d", "d"))
+
+	fitted, report := template.Fit()
+	if err := fitted.Validate(); err != nil {
+		t.Error(err)
+	}
+	if len(report) != 2 || report[0].Path != "text" || report[1].Path != "buttons[3]" {
+		t.Errorf("unexpected report: %v", report)
+	}
+}
+
+func TestListFit(t *testing.T) {
+	template := ListTemplate{}
+	for i := 0; i < 5; i++ {
+		template.AddElement(Element{Title: strings.Repeat("é", ListTemplateTitleLengthLimit+1)})
+	}
+	template.AddButton(NewPostbackButton("More", "more"), NewPostbackButton("Less", "less"))
+
+	fitted, report := template.Fit()
+	if err := fitted.Validate(); err != nil {
+		t.Error(err)
+	}
+	if len(fitted.Elements) != ListTemplateElementsLimit || len(fitted.Buttons) != ListTemplateButtonsLimit {
+		t.Error("excess elements or buttons are not dropped")
+	}
+	if len(report) != 6 {
+		t.Errorf("expected 6 changes, got %v", report)
+	}
+}
@@ -1,6 +1,10 @@
 package template
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	messenger "github.com/hellowearemito/go-messenger-structs"
+)
 
 const TemplateTypeGeneric TemplateType = "generic"
 
@@ -18,11 +22,11 @@ func (g GenericTemplate) Validate() error {
 		return ErrBubblesLimitExceeded
 	}
 	for _, elem := range g.Elements {
-		if len(elem.Title) > GenericTemplateTitleLengthLimit {
+		if messenger.ExceedsLimit(elem.Title, GenericTemplateTitleLengthLimit) {
 			return ErrTitleLengthExceeded
 		}
 
-		if len(elem.Subtitle) > GenericTemplateSubtitleLengthLimit {
+		if messenger.ExceedsLimit(elem.Subtitle, GenericTemplateSubtitleLengthLimit) {
 			return ErrSubtitleLengthExceeded
 		}
 
@@ -31,7 +35,7 @@ func (g GenericTemplate) Validate() error {
 		}
 
 		for _, button := range elem.Buttons {
-			if len(button.Title) > GenericTemplateCallToActionTitleLimit {
+			if messenger.ExceedsLimit(button.Title, GenericTemplateCallToActionTitleLimit) {
 				return ErrCallToActionTitleLengthExceeded
 			}
 		}
@@ -0,0 +1,35 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenericValidateMultilingual(t *testing.T) {
+	template := &GenericTemplate{}
+	template.AddElement(Element{
+		Title:    strings.Repeat("Árvíztűrő ", 4) + "tükör",
+		Subtitle: strings.Repeat("ünnepi ajánlat 🎉🇭🇺 ", 4),
+		Buttons:  []Button{NewPostbackButton("Megnézem 👀", "view")},
+	})
+	if err := template.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	template.Elements[0].Title = strings.Repeat("😀", GenericTemplateTitleLengthLimit+1)
+	if err := template.Validate(); err != ErrTitleLengthExceeded {
+		t.Errorf("expected ErrTitleLengthExceeded, got %v", err)
+	}
+
+	template.Elements[0].Title = "こんにちは"
+	template.Elements[0].Subtitle = strings.Repeat("ö", GenericTemplateSubtitleLengthLimit+1)
+	if err := template.Validate(); err != ErrSubtitleLengthExceeded {
+		t.Errorf("expected ErrSubtitleLengthExceeded, got %v", err)
+	}
+
+	template.Elements[0].Subtitle = ""
+	template.Elements[0].Buttons[0].Title = strings.Repeat("👍🏾", GenericTemplateCallToActionTitleLimit+1)
+	if err := template.Validate(); err != ErrCallToActionTitleLengthExceeded {
+		t.Errorf("expected ErrCallToActionTitleLengthExceeded, got %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package template
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	messenger "github.com/hellowearemito/go-messenger-structs"
+)
 
 // Type
 const (
@@ -28,6 +32,40 @@ func (ListTemplate) SupportsButtons() bool {
 	return true
 }
 
+func (l ListTemplate) Validate() error {
+	if len(l.Elements) > ListTemplateElementsLimit {
+		return ErrElementsLimitExceeded
+	}
+	if len(l.Buttons) > ListTemplateButtonsLimit {
+		return ErrListButtonsLimitExceeded
+	}
+	for _, button := range l.Buttons {
+		if messenger.ExceedsLimit(button.Title, GenericTemplateCallToActionTitleLimit) {
+			return ErrCallToActionTitleLengthExceeded
+		}
+	}
+	for _, elem := range l.Elements {
+		if messenger.ExceedsLimit(elem.Title, ListTemplateTitleLengthLimit) {
+			return ErrListTitleLengthExceeded
+		}
+
+		if messenger.ExceedsLimit(elem.Subtitle, ListTemplateSubtitleLengthLimit) {
+			return ErrListSubtitleLengthExceeded
+		}
+
+		if len(elem.Buttons) > ListTemplateButtonsLimit {
+			return ErrListButtonsLimitExceeded
+		}
+
+		for _, button := range elem.Buttons {
+			if messenger.ExceedsLimit(button.Title, GenericTemplateCallToActionTitleLimit) {
+				return ErrCallToActionTitleLengthExceeded
+			}
+		}
+	}
+	return nil
+}
+
 func (l *ListTemplate) Decode(d json.RawMessage) error {
 	t := ListTemplate{}
 	err := json.Unmarshal(d, &t)
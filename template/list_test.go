@@ -1,6 +1,9 @@
 package template
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestListType(t *testing.T) {
 	template := &ListTemplate{}
@@ -11,3 +14,25 @@ func TestListType(t *testing.T) {
 		t.Error("List template supports buttons, but reports otherwise")
 	}
 }
+
+func TestListTemplateButtonTitle(t *testing.T) {
+	long := Button{Title: strings.Repeat("👍🏾", GenericTemplateCallToActionTitleLimit+1)}
+
+	template := &ListTemplate{}
+	template.AddButton(Button{Title: strings.Repeat("👍🏾", GenericTemplateCallToActionTitleLimit)})
+	if err := template.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	template = &ListTemplate{}
+	template.AddButton(long)
+	if err := template.Validate(); err != ErrCallToActionTitleLengthExceeded {
+		t.Error(err)
+	}
+
+	template = &ListTemplate{}
+	template.AddElement(Element{Title: "title", Buttons: []Button{long}})
+	if err := template.Validate(); err != ErrCallToActionTitleLengthExceeded {
+		t.Error(err)
+	}
+}
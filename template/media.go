@@ -2,6 +2,12 @@ package template
 
 const TemplateTypeMedia TemplateType = "media"
 
+// Media types of MediaElement
+const (
+	MediaTypeImage = "image"
+	MediaTypeVideo = "video"
+)
+
 type MediaTemplate struct {
 	TemplateBase
 	Elements []MediaElement `json:"elements"`
@@ -11,9 +17,25 @@ func (MediaTemplate) Type() TemplateType {
 	return TemplateTypeMedia
 }
 
+func (m *MediaTemplate) AddElement(e ...MediaElement) {
+	m.Elements = append(m.Elements, e...)
+}
+
 type MediaElement struct {
 	MediaType    string   `json:"media_type"`
 	AttachmentID string   `json:"attachment_id,omitempty"`
 	URL          string   `json:"url,omitempty"`
 	Buttons      []Button `json:"buttons,omitempty"`
 }
+
+// NewMediaElement creates a media element referencing an image or video uploaded with the Attachment Upload API
+func NewMediaElement(mediaType string, attachmentID string) MediaElement {
+	return MediaElement{
+		MediaType:    mediaType,
+		AttachmentID: attachmentID,
+	}
+}
+
+func (e *MediaElement) AddButton(b ...Button) {
+	e.Buttons = append(e.Buttons, b...)
+}
@@ -10,7 +10,12 @@ const (
 	GenericTemplateCallToActionItemsLimit = 3
 	GenericTemplateBubblesPerMessageLimit = 10
 
-	ButtonTemplateButtonsLimit = 3
+	ButtonTemplateButtonsLimit      = 3
+	ButtonTemplateTextLengthLimit   = 640
+	ListTemplateElementsLimit       = 4
+	ListTemplateButtonsLimit        = 1
+	ListTemplateTitleLengthLimit    = 80
+	ListTemplateSubtitleLengthLimit = 80
 )
 
 var (
@@ -19,6 +24,11 @@ var (
 	ErrCallToActionTitleLengthExceeded = errors.New("Template call to action title exceeds the 20 character limit")
 	ErrButtonsLimitExceeded            = errors.New("Limit of 3 buttons exceeded")
 	ErrBubblesLimitExceeded            = errors.New("Limit of 10 bubbles per message exceeded")
+	ErrTextLengthExceeded              = errors.New("Template text exceeds the 640 character limit")
+	ErrElementsLimitExceeded           = errors.New("Limit of 4 list elements exceeded")
+	ErrListButtonsLimitExceeded        = errors.New("Limit of 1 list button exceeded")
+	ErrListTitleLengthExceeded         = errors.New("List element title exceeds the 80 character limit")
+	ErrListSubtitleLengthExceeded      = errors.New("List element subtitle exceeds the 80 character limit")
 )
 
 type TemplateType string
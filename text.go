@@ -0,0 +1,88 @@
+package messenger
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharacterCount returns the number of user-perceived characters (grapheme clusters) in s.
+// Messenger limits (titles, subtitles, texts...) are measured in characters, not in bytes,
+// so an accented letter or an emoji sequence like a flag or a family counts as one character.
+func CharacterCount(s string) int {
+	count := 0
+	for len(s) > 0 {
+		s = s[characterLength(s):]
+		count++
+	}
+	return count
+}
+
+// ExceedsLimit reports whether s is longer than limit characters.
+func ExceedsLimit(s string, limit int) bool {
+	// every character takes at least one byte, so short strings can be accepted without segmentation
+	if len(s) <= limit {
+		return false
+	}
+	return CharacterCount(s) > limit
+}
+
+// characterLength returns the length in bytes of the first grapheme cluster of s.
+// It implements the subset of the Unicode segmentation rules (UAX #29) relevant for chat texts:
+// CR LF, combining marks, variation selectors, emoji modifiers, tags, ZWJ sequences and flags.
+func characterLength(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if r == '\r' && len(s) > 1 && s[1] == '\n' {
+		return 2
+	}
+	if r == '\r' || r == '\n' {
+		return size
+	}
+
+	regionalIndicators := 0
+	if isRegionalIndicator(r) {
+		regionalIndicators = 1
+	}
+	prev := r
+
+	for size < len(s) {
+		next, n := utf8.DecodeRuneInString(s[size:])
+		switch {
+		case isExtend(next):
+		case prev == zeroWidthJoiner && unicode.Is(unicode.So, next):
+		case isRegionalIndicator(next) && regionalIndicators == 1:
+			regionalIndicators++
+		default:
+			return size
+		}
+		size += n
+		prev = next
+	}
+	return size
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// isExtend reports whether r never starts a new character.
+func isExtend(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner:
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F, r >= 0xE0100 && r <= 0xE01EF: // variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // emoji skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tags
+		return true
+	case r >= 0x1160 && r <= 0x11FF: // hangul jamo medial vowels and final consonants
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,127 @@
+package messenger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCharacterCount(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello", 5},
+		{"hungarian", "Árvíztűrő tükörfúrógép", 22},
+		{"decomposed accents", "e\u0301te\u0301", 3},
+		{"japanese", "こんにちは", 5},
+		{"vietnamese", "Tiếng Việt", 10},
+		{"emoji", "👍👍", 2},
+		{"skin tone", "👍🏽", 1},
+		{"variation selector", "❤️", 1},
+		{"zwj family", "👨‍👩‍👧‍👦", 1},
+		{"flags", "🇭🇺🇫🇷", 2},
+		{"odd regional indicators", "🇭🇺🇫", 2},
+		{"crlf", "a\r\nb", 3},
+		{"mixed", "Szia 👋🏻!", 7},
+	}
+
+	for _, test := range tests {
+		if got := CharacterCount(test.text); got != test.want {
+			t.Errorf("%s: CharacterCount(%q) = %d, want %d", test.name, test.text, got, test.want)
+		}
+	}
+}
+
+func TestExceedsLimit(t *testing.T) {
+	if ExceedsLimit(strings.Repeat("ő", 45), 45) {
+		t.Error("45 hungarian characters reported as exceeding the limit of 45")
+	}
+	if !ExceedsLimit(strings.Repeat("ő", 46), 45) {
+		t.Error("46 hungarian characters not reported as exceeding the limit of 45")
+	}
+	if ExceedsLimit(strings.Repeat("👨‍👩‍👧", 10), 10) {
+		t.Error("10 emoji sequences reported as exceeding the limit of 10")
+	}
+}
+
+func TestSendMessageValidate(t *testing.T) {
+	m := SendMessage{Text: strings.Repeat("😀", SendMessageTextLengthLimit)}
+	if err := m.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	m.Text += "😀"
+	if err := m.Validate(); err != ErrTextLengthExceeded {
+		t.Errorf("expected ErrTextLengthExceeded, got %v", err)
+	}
+
+	m = SendMessage{QuickReplies: []QuickReply{{ContentType: ContentTypeText, Title: "Igen, köszönöm 🙏🏼"}}}
+	if err := m.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	m.QuickReplies[0].Title = strings.Repeat("é", QuickReplyTitleLengthLimit+1)
+	if err := m.Validate(); err != ErrQuickReplyTitleLengthExceeded {
+		t.Errorf("expected ErrQuickReplyTitleLengthExceeded, got %v", err)
+	}
+
+	m.QuickReplies = make([]QuickReply, QuickRepliesLimit+1)
+	if err := m.Validate(); err != ErrQuickRepliesLimitExceeded {
+		t.Errorf("expected ErrQuickRepliesLimitExceeded, got %v", err)
+	}
+}
+
+func TestGreetingValidate(t *testing.T) {
+	g := Greeting{Locale: DefaultLocale, Text: strings.Repeat("ű", GreetingTextLengthLimit)}
+	if err := g.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	g.Text += "ű"
+	if err := g.Validate(); err == nil {
+		t.Error("greeting exceeding the limit is accepted")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text      string
+		limit     int
+		want      string
+		truncated bool
+	}{
+		{"short", 10, "short", false},
+		{"Árvíztűrő tükörfúrógép", 10, "Árvíztűrő…", true},
+		{"Árvíztűrő tükörfúrógép", 11, "Árvíztűrő…", true},
+		{"👨‍👩‍👧‍👦👨‍👩‍👧‍👦👨‍👩‍👧‍👦", 2, "👨‍👩‍👧‍👦…", true},
+		{"abc", 0, "", true},
+	}
+
+	for _, test := range tests {
+		got, truncated := Truncate(test.text, test.limit)
+		if got != test.want || truncated != test.truncated {
+			t.Errorf("Truncate(%q, %d) = %q, %v, want %q, %v", test.text, test.limit, got, truncated, test.want, test.truncated)
+		}
+		if CharacterCount(got) > test.limit {
+			t.Errorf("Truncate(%q, %d) exceeds the limit", test.text, test.limit)
+		}
+	}
+}
+
+func TestFitQuickReplies(t *testing.T) {
+	quickReplies := make([]QuickReply, QuickRepliesLimit+2)
+	quickReplies[0].Title = "Köszönöm szépen, igen 🙏"
+
+	fitted, report := FitQuickReplies(quickReplies)
+	if err := (SendMessage{QuickReplies: fitted}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if len(report) != 3 || report[0].Action != FitActionTruncated || report[1].Action != FitActionDropped {
+		t.Errorf("unexpected report: %v", report)
+	}
+	if quickReplies[0].Title != "Köszönöm szépen, igen 🙏" {
+		t.Error("original quick replies are modified")
+	}
+}
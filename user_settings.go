@@ -0,0 +1,44 @@
+package messenger
+
+import "fmt"
+
+// CustomUserSettingsPath is the path of the user level persistent menu API
+// https://developers.facebook.com/docs/messenger-platform/send-messages/persistent-menu#user_level_menu
+const CustomUserSettingsPath = "me/custom_user_settings"
+
+// CustomUserSettings represents the request setting the persistent menu of a single user
+type CustomUserSettings struct {
+	PSID           string           `json:"psid"`
+	PersistentMenu []PersistentMenu `json:"persistent_menu"`
+}
+
+// UserPersistentMenu represents the persistent menus shown to a user
+type UserPersistentMenu struct {
+	UserLevel []PersistentMenu `json:"user_level_persistent_menu,omitempty"`
+	PageLevel []PersistentMenu `json:"page_level_persistent_menu,omitempty"`
+}
+
+// userPersistentMenuResponse represents the response of the custom user settings GET request
+type userPersistentMenuResponse struct {
+	Data []UserPersistentMenu `json:"data"`
+}
+
+// Validate validates the persistent menus of the user like Settings.Validate does
+func (s CustomUserSettings) Validate() error {
+	var errs ValidationErrors
+	if s.PSID == "" {
+		errs.add("psid", "is required")
+	}
+	if len(s.PersistentMenu) == 0 {
+		errs.add(SettingsFieldPersistentMenu.String(), "is required")
+	}
+
+	locales := make([]string, len(s.PersistentMenu))
+	for i, m := range s.PersistentMenu {
+		m.validate(fmt.Sprintf("%s[%d]", SettingsFieldPersistentMenu, i), &errs)
+		locales[i] = m.Locale
+	}
+	validateLocales(SettingsFieldPersistentMenu.String(), locales, &errs)
+
+	return errs.err()
+}